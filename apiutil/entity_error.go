@@ -1,6 +1,12 @@
 package apiutil
 
-import "github.com/arquivei/foundationkit/errors"
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/arquivei/foundationkit/errors"
+)
 
 const (
 	// ErrCodeInternal is returned when an internal error happens.
@@ -13,13 +19,39 @@ const (
 	ErrCodeTimeout = errors.Code("REQUEST_TIMEOUT")
 )
 
+func init() {
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeInternal,
+		HTTPStatus:  http.StatusInternalServerError,
+		Description: "An internal error happened.",
+		Owner:       "foundationkit/apiutil",
+	})
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeBadRequest,
+		HTTPStatus:  http.StatusBadRequest,
+		Description: "The request data is not valid.",
+		Owner:       "foundationkit/apiutil",
+	})
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeTimeout,
+		HTTPStatus:  http.StatusRequestTimeout,
+		Retryable:   true,
+		Description: "The request timed out.",
+		Owner:       "foundationkit/apiutil",
+	})
+}
+
 // ErrorDescription represents the detailed returned error in all APIs
 type ErrorDescription struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	// Errors describes each error of an errors.Multi, like the errors of the
+	// items of a batch.
+	Errors []ErrorDescription `json:"errors,omitempty"`
 }
 
-// ParseError parses @err in a ErrorDescription
+// ParseError parses @err in a ErrorDescription. If @err wraps an errors.Multi,
+// each of its errors is also parsed in ErrorDescription.Errors.
 func ParseError(err error) ErrorDescription {
 	// This should never happen, but...
 	if err == nil {
@@ -28,10 +60,17 @@ func ParseError(err error) ErrorDescription {
 			Message: "trying to encode nil error",
 		}
 	}
-	return ErrorDescription{
+	description := ErrorDescription{
 		Code:    getErrorCode(err).String(),
 		Message: errors.GetRootErrorWithKV(err).Error(),
 	}
+	var multi *errors.Multi
+	if stderrors.As(err, &multi) {
+		for _, child := range multi.Errs {
+			description.Errors = append(description.Errors, ParseError(child))
+		}
+	}
+	return description
 }
 
 func getErrorCode(err error) errors.Code {
@@ -51,3 +90,17 @@ func getErrorCodeBasedOnSeverity(code errors.Severity) errors.Code {
 		return ErrCodeInternal
 	}
 }
+
+// ParseWireError is a ParseErrorFunc that parses @err in an
+// errors.WireErrorResponse. NewHTTPErrorJSONEncoder marks these responses with
+// the errors.WireErrorHeader, so httpcomm reconstructs the error. The response
+// exposes the internal ops and KVs of @err, so only use it between trusted
+// services.
+func ParseWireError(_ context.Context, err error) interface{} {
+	if err == nil {
+		err = errors.E(ErrCodeInternal, "trying to encode nil error")
+	}
+	return errors.WireErrorResponse{
+		Error: errors.ToWire(err),
+	}
+}
@@ -32,6 +32,9 @@ func NewHTTPErrorJSONEncoder(
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
 		resp := parseErrorFunc(ctx, err)
+		if _, ok := resp.(errors.WireErrorResponse); ok {
+			w.Header().Set(errors.WireErrorHeader, errors.WireErrorFormat)
+		}
 
 		w.WriteHeader(getHTTPStatus(err))
 		if encodeErr := json.NewEncoder(w).Encode(resp); encodeErr != nil {
@@ -46,9 +49,16 @@ func NewHTTPErrorJSONEncoder(
 // GetDefaultErrorHTTPStatusCode returns an HTTP status code base on an error.
 //
 // This is a default implementation that sets the satus code base on the error
-// severity.
+// severity. If the error has no severity set, the HTTP status of its code in
+// the errors catalog is used.
 // nolint:gocritic
 func GetDefaultErrorHTTPStatusCode(err error) (s int) {
+	if errors.GetExplicitSeverity(err) == errors.SeverityUnset {
+		if info, ok := errors.LookupCode(errors.GetCode(err)); ok && info.HTTPStatus != 0 {
+			return info.HTTPStatus
+		}
+	}
+
 	switch errors.GetSeverity(err) {
 	case errors.SeverityInput:
 		return http.StatusBadRequest
@@ -0,0 +1,53 @@
+package apiutil
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseError(t *testing.T) {
+	assert.Equal(t, ErrorDescription{
+		Code:    "INTERNAL_ERROR",
+		Message: "trying to encode nil error",
+	}, ParseError(nil))
+
+	assert.Equal(t, ErrorDescription{
+		Code:    "BAD_REQUEST",
+		Message: "invalid name [item=1]",
+	}, ParseError(errors.E(errors.Op("op"), "invalid name", errors.SeverityInput, errors.KV("item", 1))))
+}
+
+func TestParseError_Multi(t *testing.T) {
+	err := errors.E(errors.Op("batch"), errors.Join(
+		errors.E("invalid name", errors.SeverityInput, errors.KV("item", 1)),
+		errors.E("invalid email", errors.SeverityInput, errors.Code("INVALID_EMAIL"), errors.KV("item", 2)),
+	))
+
+	assert.Equal(t, ErrorDescription{
+		Code:    "INVALID_EMAIL",
+		Message: "2 errors: invalid name [item=1]; invalid email [item=2]",
+		Errors: []ErrorDescription{
+			{Code: "BAD_REQUEST", Message: "invalid name [item=1]"},
+			{Code: "INVALID_EMAIL", Message: "invalid email [item=2]"},
+		},
+	}, ParseError(err))
+}
+
+func TestGetDefaultErrorHTTPStatusCode(t *testing.T) {
+	code := errors.RegisterCode(errors.CodeInfo{
+		Code:       "APIUTIL_TEST_CONFLICT",
+		HTTPStatus: http.StatusConflict,
+	})
+
+	assert.Equal(t, http.StatusConflict, GetDefaultErrorHTTPStatusCode(errors.E(code, "my error")))
+	assert.Equal(t, http.StatusBadRequest, GetDefaultErrorHTTPStatusCode(errors.E(code, errors.SeverityInput, "my error")),
+		"explicit severity wins")
+	assert.Equal(t, http.StatusInternalServerError, GetDefaultErrorHTTPStatusCode(errors.E(code, errors.SeverityRuntime, "my error")),
+		"explicit severity wins")
+	assert.Equal(t, http.StatusRequestTimeout, GetDefaultErrorHTTPStatusCode(errors.E(ErrCodeTimeout, "my error")))
+	assert.Equal(t, http.StatusBadRequest, GetDefaultErrorHTTPStatusCode(errors.E(errors.SeverityInput, "my error")))
+	assert.Equal(t, http.StatusInternalServerError, GetDefaultErrorHTTPStatusCode(errors.E("my error")))
+}
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof" // Sadly, this also changes the DefaultMux to have the pprof URLs
+	"strings"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+)
+
+// AdminConfig configures the admin HTTP server that serves metrics, probes
+// and debug information.
+type AdminConfig struct {
+	// Addr is the address the admin server binds to, in the form "host:port".
+	Addr string `default:":9000"`
+	// TLS enables HTTPS on the admin server. Both files must be set.
+	TLS struct {
+		CertFile string
+		KeyFile  string
+	}
+	// ReadHeaderTimeout is the amount of time allowed to read request headers.
+	ReadHeaderTimeout time.Duration `default:"60s"`
+	// Auth protects the /debug/ routes. Probes and metrics are always open.
+	Auth AdminAuthConfig
+}
+
+// NewDefaultAdminConfig returns a new AdminConfig binding to DefaultAdminPort on all interfaces.
+func NewDefaultAdminConfig() AdminConfig {
+	return AdminConfig{
+		Addr:              ":" + DefaultAdminPort,
+		ReadHeaderTimeout: 60 * time.Second,
+	}
+}
+
+func (c AdminConfig) isTLS() bool {
+	return c.TLS.CertFile != "" || c.TLS.KeyFile != ""
+}
+
+// AdminHandle registers a handler for the given pattern on the admin server.
+// It follows the same rules as http.ServeMux and panics if the pattern
+// conflicts with an already registered one.
+func (a *App) AdminHandle(pattern string, handler http.Handler) {
+	a.adminMux.Handle(pattern, handler)
+	log.Trace().Str("pattern", pattern).Msg("[app] Admin handler registered.")
+}
+
+// AdminHandleFunc registers a handler function for the given pattern on the admin server.
+func (a *App) AdminHandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	a.AdminHandle(pattern, http.HandlerFunc(handler))
+}
+
+// AdminAddr returns the address the admin server is listening on.
+func (a *App) AdminAddr() net.Addr {
+	return a.adminListener.Addr()
+}
+
+func (a *App) registerDefaultAdminHandlers() {
+	a.AdminHandle("/metrics", promhttp.Handler())
+
+	bi := ReadBuildInfo()
+	bi.observe()
+	a.AdminHandle("/version", newBuildInfoHandler(bi))
+
+	a.AdminHandle("/healthy", newProbeGroupHandler(&a.Healthy, "Healthiness", http.StatusInternalServerError))
+	a.AdminHandle("/ready", newProbeGroupHandler(&a.Ready, "Readiness", http.StatusServiceUnavailable))
+	a.AdminHandle("/startup", newProbeGroupHandler(&a.Startup, "Startup", http.StatusServiceUnavailable))
+
+	a.AdminHandle("/debug/config/reload", newConfigReloadHandler(ReloadConfig))
+
+	a.AdminHandleFunc("/debug/pprof/", pprof.Index)
+	a.AdminHandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	a.AdminHandleFunc("/debug/pprof/profile", pprof.Profile)
+	a.AdminHandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	a.AdminHandleFunc("/debug/pprof/trace", pprof.Trace)
+	a.AdminHandleFunc("/debug/dump/goroutines", dumpGoroutines)
+	a.AdminHandleFunc("/debug/dump/memory", dumpMemProfile)
+	a.AdminHandleFunc("/debug/dump/memstats", dumpMemStats)
+	a.AdminHandle("/debug/config", newEffectiveConfigHandler(EffectiveConfig))
+	a.AdminHandle("/debug/loglevel", newLogLevelHandler())
+	a.AdminHandle("/debug/drain", newDrainHandler(a, (*App).Drain))
+	a.AdminHandle("/debug/undrain", newDrainHandler(a, (*App).Undrain))
+	a.AdminHandle("/debug/errors", newErrorCodesHandler())
+}
+
+// startAdminServer binds the admin server and serves it on a go-routine.
+// Binding errors are returned so the app fails to start instead of running
+// without probes and metrics.
+func (a *App) startAdminServer(config AdminConfig) error {
+	const op = errors.Op("app.App.startAdminServer")
+
+	var tlsConfig *tls.Config
+	if config.isTLS() {
+		cert, err := tls.LoadX509KeyPair(config.TLS.CertFile, config.TLS.KeyFile)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	if err := config.Auth.validate(); err != nil {
+		return errors.E(op, err)
+	}
+
+	if config.Auth.ClientCAFile != "" {
+		if tlsConfig == nil {
+			return errors.E(op, "client certificate auth requires TLS")
+		}
+		clientCAs, err := loadCertPool(config.Auth.ClientCAFile)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		// Client certificates are only required by the /debug/ routes, so
+		// the handshake doesn't fail without one.
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return errors.E(op, err, errors.KV("addr", config.Addr))
+	}
+	a.adminListener = listener
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	server := &http.Server{
+		Handler:           protectDebugRoutes(a.adminMux, config.Auth),
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+	}
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			a.logger.Error().Err(errors.E(op, err)).Msg("[app] Admin server stopped unexpectedly.")
+		}
+	}()
+
+	// The admin server is the last thing to go down so probes and metrics
+	// are available during the whole shutdown. It's pushed into the shutdown
+	// heap only when the shutdown starts so it runs after any other handler
+	// with the lowest priority.
+	a.adminShutdownHandler = &ShutdownHandler{
+		Name:     "fkit/app/admin",
+		Priority: ShutdownPriority(0),
+		Handler:  server.Shutdown,
+		Policy:   ErrorPolicyWarn,
+	}
+
+	log.Trace().
+		Str("addr", listener.Addr().String()).
+		Bool("tls", config.isTLS()).
+		Bool("auth", config.Auth.isEnabled()).
+		Msg("[app] Admin server started.")
+
+	return nil
+}
+
+// newProbeGroupHandler returns a handler that replies with the state of the
+// group. The reply is a plain text "OK" or the cause of the failure, unless
+// JSON is requested by the Accept header or the "verbose" query parameter,
+// in which case every probe is listed with its details.
+func newProbeGroupHandler(group *ProbeGroup, kind string, notOkStatus int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := group.Status()
+
+		httpStatus := http.StatusOK
+		if !status.Ok {
+			httpStatus = notOkStatus
+		}
+
+		if wantsVerboseProbes(r) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(httpStatus)
+			//nolint:errcheck
+			json.NewEncoder(w).Encode(status)
+		} else if status.Ok {
+			w.WriteHeader(httpStatus)
+			//nolint:errcheck
+			w.Write([]byte("OK"))
+		} else {
+			w.WriteHeader(httpStatus)
+			//nolint:errcheck
+			w.Write([]byte(status.cause()))
+		}
+
+		log.Trace().Bool("ok", status.Ok).Msgf("[app] %s probe replied.", kind)
+	})
+}
+
+func wantsVerboseProbes(r *http.Request) bool {
+	if _, ok := r.URL.Query()["verbose"]; ok {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() Config {
+	config := NewDefaultConfig()
+	config.Admin.Addr = "127.0.0.1:0"
+	return config
+}
+
+// newTestApp returns an App with the admin server on an ephemeral port. The
+// App is shut down when the test finishes.
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	return newTestAppWithConfig(t, newTestConfig())
+}
+
+func newTestAppWithConfig(t *testing.T, config Config) *App {
+	t.Helper()
+	a, err := NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = a.Shutdown(context.Background()) })
+	return a
+}
+
+func adminGet(t *testing.T, a *App, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get("http://" + a.AdminAddr().String() + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestAdminHandle(t *testing.T) {
+	a := newTestApp(t)
+
+	a.AdminHandleFunc("/custom", func(w http.ResponseWriter, _ *http.Request) {
+		//nolint:errcheck
+		w.Write([]byte("custom"))
+	})
+
+	status, body := adminGet(t, a, "/custom")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "custom", body)
+
+	status, body = adminGet(t, a, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "fkit/app", body)
+
+	require.NoError(t, a.Shutdown(context.Background()))
+
+	_, err := http.Get("http://" + a.AdminAddr().String() + "/custom")
+	assert.Error(t, err, "admin server should be closed after shutdown")
+}
+
+func TestNewWithConfig_AddressInUse(t *testing.T) {
+	a := newTestApp(t)
+
+	config := newTestConfig()
+	config.Admin.Addr = a.AdminAddr().String()
+	_, err := NewWithConfig(context.Background(), config)
+	assert.ErrorContains(t, err, "app.App.startAdminServer")
+}
+
+func TestNewWithConfig_InvalidTLS(t *testing.T) {
+	config := newTestConfig()
+	config.Admin.TLS.CertFile = "does-not-exist.pem"
+	_, err := NewWithConfig(context.Background(), config)
+	assert.ErrorContains(t, err, "app.App.startAdminServer")
+}
+
+func TestProbeGroupHandler_Verbose(t *testing.T) {
+	a := newTestApp(t)
+
+	p := a.Ready.MustNewProbe("database", true)
+	p.SetError(errors.New("connection refused"))
+
+	for _, path := range []string{"/ready?verbose", "/ready?verbose=1"} {
+		status, body := adminGet(t, a, path)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+
+		var got ProbeGroupStatus
+		require.NoError(t, json.Unmarshal([]byte(body), &got))
+		assert.False(t, got.Ok)
+		require.Len(t, got.Probes, 2)
+		assert.Equal(t, "database", got.Probes[0].Name)
+		assert.Equal(t, "connection refused", got.Probes[0].Reason)
+		assert.False(t, got.Probes[0].LastTransition.IsZero())
+		assert.Equal(t, "fkit/app", got.Probes[1].Name)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+a.AdminAddr().String()+"/healthy", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	status, body := adminGet(t, a, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "database (connection refused),fkit/app", body)
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+// AdminAuthConfig configures the authentication of the /debug/ routes of the
+// admin server, like pprof and the memory dumps. Any configured method grants
+// access. If none is configured the routes are open.
+type AdminAuthConfig struct {
+	// BearerToken is accepted in the "Authorization: Bearer <token>" header.
+	BearerToken string `secret:"true"`
+	// Username and Password are accepted as HTTP basic auth. Both must be
+	// set, setting only one of them is an error.
+	Username string
+	Password string `secret:"true"`
+	// ClientCAFile is a PEM file with the CAs of the accepted client
+	// certificates. It requires the admin server to use TLS.
+	ClientCAFile string
+}
+
+func (c AdminAuthConfig) isEnabled() bool {
+	return c.BearerToken != "" || c.Username != "" || c.Password != "" || c.ClientCAFile != ""
+}
+
+// validate checks the config is not half-set, which would either leave the
+// routes open or accept empty credentials.
+func (c AdminAuthConfig) validate() error {
+	if (c.Username == "") != (c.Password == "") {
+		return errors.New("basic auth requires both username and password")
+	}
+	return nil
+}
+
+// authorize tells if the request is authenticated by any configured method.
+func (c AdminAuthConfig) authorize(r *http.Request) bool {
+	if c.BearerToken != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && secureEqual(token, c.BearerToken) {
+			return true
+		}
+	}
+	if c.Username != "" && c.Password != "" {
+		if username, password, ok := r.BasicAuth(); ok &&
+			secureEqual(username, c.Username) && secureEqual(password, c.Password) {
+			return true
+		}
+	}
+	if c.ClientCAFile != "" {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// protectDebugRoutes returns a handler that requires auth for the /debug/
+// routes and passes any other request straight to next.
+func protectDebugRoutes(next http.Handler, auth AdminAuthConfig) http.Handler {
+	if !auth.isEnabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isDebugRoute(r.URL.Path) && !auth.authorize(r) {
+			if auth.Username != "" || auth.Password != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+			} else if auth.BearerToken != "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isDebugRoute tells if p is a /debug/ route. The path is cleaned first since
+// the mux would otherwise redirect unclean paths to the debug routes.
+func isDebugRoute(p string) bool {
+	p = path.Clean("/" + p)
+	return p == "/debug" || strings.HasPrefix(p, "/debug/")
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	const op = errors.Op("app.loadCertPool")
+
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.E(op, "no certificates found", errors.KV("file", file))
+	}
+	return pool, nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectDebugRoutes(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := protectDebugRoutes(next, AdminAuthConfig{
+		BearerToken: "token",
+		Username:    "admin",
+		Password:    "secret",
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		setup  func(r *http.Request)
+		status int
+	}{
+		{name: "probes are open", path: "/ready", status: http.StatusOK},
+		{name: "metrics are open", path: "/metrics", status: http.StatusOK},
+		{name: "debug without auth", path: "/debug/pprof/", status: http.StatusUnauthorized},
+		{name: "unclean debug path", path: "/metrics/../debug/dump/memory", status: http.StatusUnauthorized},
+		{
+			name:   "bearer token",
+			path:   "/debug/pprof/",
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") },
+			status: http.StatusOK,
+		},
+		{
+			name:   "wrong bearer token",
+			path:   "/debug/pprof/",
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "basic auth",
+			path:   "/debug/dump/goroutines",
+			setup:  func(r *http.Request) { r.SetBasicAuth("admin", "secret") },
+			status: http.StatusOK,
+		},
+		{
+			name:   "wrong basic auth",
+			path:   "/debug/dump/goroutines",
+			setup:  func(r *http.Request) { r.SetBasicAuth("admin", "wrong") },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "client certificate without client CA configured",
+			path: "/debug/pprof/",
+			setup: func(r *http.Request) {
+				r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{}}}
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.URL.Path = tt.path
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="admin"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAdminAuthConfig_ClientCertificate(t *testing.T) {
+	auth := AdminAuthConfig{ClientCAFile: "ca.pem"}
+
+	r := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	assert.False(t, auth.authorize(r))
+
+	r.TLS = &tls.ConnectionState{}
+	assert.False(t, auth.authorize(r), "unverified connections must be rejected")
+
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{}}}
+	assert.True(t, auth.authorize(r))
+}
+
+func TestNewWithConfig_ClientCertificateRequiresTLS(t *testing.T) {
+	config := newTestConfig()
+	config.Admin.Auth.ClientCAFile = "ca.pem"
+	_, err := NewWithConfig(context.Background(), config)
+	assert.EqualError(t, err, "app.App.startAdminServer: client certificate auth requires TLS")
+}
+
+func TestNewWithConfig_HalfSetBasicAuth(t *testing.T) {
+	for _, auth := range []AdminAuthConfig{
+		{Username: "admin"},
+		{Password: "secret"},
+	} {
+		config := newTestConfig()
+		config.Admin.Auth = auth
+		_, err := NewWithConfig(context.Background(), config)
+		assert.EqualError(t, err, "app.App.startAdminServer: basic auth requires both username and password")
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	config := newTestConfig()
+	config.Admin.Auth.BearerToken = "token"
+	a := newTestAppWithConfig(t, config)
+
+	status, _ := adminGet(t, a, "/debug/dump/memstats")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = adminGet(t, a, "/debug/config/reload")
+	assert.Equal(t, http.StatusUnauthorized, status, "config reload must be protected")
+
+	status, _ = adminGet(t, a, "/healthy")
+	assert.Equal(t, http.StatusOK, status)
+}
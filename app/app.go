@@ -3,14 +3,15 @@ package app
 import (
 	"container/heap"
 	"context"
+	"net"
 	"net/http"
-	"net/http/pprof" // Sadly, this also changes the DefaultMux to have the pprof URLs
+	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/arquivei/foundationkit/errors"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -18,31 +19,99 @@ import (
 // MainLoopFunc is the functions runned by app. If it finishes, it will trigger a shutdown
 type MainLoopFunc func() error
 
+// MainLoopWithReadyFunc is a MainLoopFunc that signals by itself when the app
+// is ready by calling ready. Calling ready more than once has no effect.
+type MainLoopWithReadyFunc func(ready func()) error
+
 // App represents an application with a main loop and a shutdown routine
 type App struct {
 	logger *zerolog.Logger
 
 	Ready   ProbeGroup
 	Healthy ProbeGroup
+	Startup ProbeGroup
 
 	shutdownHandlers shutdownHeap
 	GracePeriod      time.Duration
 	ShutdownTimeout  time.Duration
 
+	// Clock is used to wait for the grace period and the loops backoff.
+	// Defaults to the real clock.
+	Clock Clock
+	// Signals replaces the OS signals received by RunAndWait, if set. It's
+	// meant for tests, like the ones using the apptest package.
+	Signals <-chan os.Signal
+
 	mainReadinessProbe  Probe
 	mainHealthnessProbe Probe
+	mainStartupProbe    Probe
+
+	loopsMu   sync.Mutex
+	loops     []*supervisedLoop
+	loopsCtx  context.Context
+	loopsErrs chan<- error
+
+	lastShutdownReportMu sync.Mutex
+	lastShutdownReport   *ShutdownReport
+
+	leakCheck         LeakCheckConfig
+	goroutineBaseline goroutineBaseline
+
+	drainMu    sync.Mutex
+	draining   bool
+	ready      bool
+	drainHooks []drainHook
+
+	adminMux             *http.ServeMux
+	adminListener        net.Listener
+	adminShutdownHandler *ShutdownHandler
 }
 
-// New returns a new App.
+// New returns a new App with an admin server listening on the given port.
 // If ctx contains a zerolog logger it is used for logging.
-// adminPort must be a valid port number or it will fail silently.
 func New(ctx context.Context, adminPort string) (*App, error) {
+	config := NewDefaultConfig()
+	config.Admin.Addr = ":" + adminPort
+	return NewWithConfig(ctx, config)
+}
+
+// Config configures an App.
+type Config struct {
+	Admin     AdminConfig
+	Runtime   RuntimeConfig
+	LeakCheck LeakCheckConfig
+}
+
+// NewDefaultConfig returns a Config with the default admin server and an untouched runtime.
+func NewDefaultConfig() Config {
+	return Config{
+		Admin:     NewDefaultAdminConfig(),
+		Runtime:   NewDefaultRuntimeConfig(),
+		LeakCheck: NewDefaultLeakCheckConfig(),
+	}
+}
+
+// NewWithConfig returns a new App configured by config. The runtime is tuned
+// before anything else, so the admin server and the app already benefit from it.
+// If the leak check is enabled, the goroutines alive at this moment are the
+// baseline the ones alive after the shutdown are compared to.
+// If ctx contains a zerolog logger it is used for logging.
+// An error is returned if the admin server can't be started.
+func NewWithConfig(ctx context.Context, config Config) (*App, error) {
 	log.Trace().Msg("[app] Creating new app")
 
+	tuneRuntime(log.Ctx(ctx), config.Runtime)
+
 	app := &App{
-		logger:  log.Ctx(ctx),
-		Ready:   NewProbeGroup(),
-		Healthy: NewProbeGroup(),
+		logger:    log.Ctx(ctx),
+		Ready:     NewProbeGroup(),
+		Healthy:   NewProbeGroup(),
+		Startup:   NewProbeGroup(),
+		adminMux:  http.NewServeMux(),
+		leakCheck: config.LeakCheck,
+	}
+	if config.LeakCheck.Enabled {
+		app.goroutineBaseline = newGoroutineBaseline()
 	}
 
 	mainReadinessProbe, err := app.Ready.NewProbe("fkit/app", false)
@@ -55,60 +124,18 @@ func New(ctx context.Context, adminPort string) (*App, error) {
 		return nil, err
 	}
 
+	mainStartupProbe, err := app.Startup.NewProbe("fkit/app", false)
+	if err != nil {
+		return nil, err
+	}
+
 	app.mainReadinessProbe = mainReadinessProbe
 	app.mainHealthnessProbe = mainHealthnessProbe
+	app.mainStartupProbe = mainStartupProbe
 
-	{ // This spwans an admin HTTP server for this
-		mux := http.NewServeMux()
-
-		mux.Handle("/metrics", promhttp.Handler())
-
-		mux.HandleFunc("/healthy", func(w http.ResponseWriter, _ *http.Request) {
-			isHealthy, cause := app.Healthy.CheckProbes()
-			if isHealthy {
-				w.WriteHeader(http.StatusOK)
-				//nolint:errcheck
-				w.Write([]byte("OK"))
-				log.Trace().Msg("[app] Healthiness probe replied: I'm healthy!")
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-				//nolint:errcheck
-				w.Write([]byte(cause))
-				log.Trace().Str("cause", cause).Msg("[app] Healthiness probe replied: I'm unhealthy.")
-			}
-		})
-
-		mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
-			isReady, cause := app.Ready.CheckProbes()
-			if isReady {
-				w.WriteHeader(http.StatusOK)
-				//nolint:errcheck
-				w.Write([]byte("OK"))
-				log.Trace().Msg("[app] Readiness probe replied: I'm ready!")
-			} else {
-				w.WriteHeader(http.StatusServiceUnavailable)
-				//nolint:errcheck
-				w.Write([]byte(cause))
-				log.Trace().Str("cause", cause).Msg("[app] Readiness probe replied: I'm unready.")
-			}
-		})
-
-		mux.HandleFunc("/debug/pprof/", http.HandlerFunc(pprof.Index))
-		mux.HandleFunc("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
-		mux.HandleFunc("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
-		mux.HandleFunc("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
-		mux.HandleFunc("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
-		mux.HandleFunc("/debug/dump/goroutines", dumpGoroutines)
-		mux.HandleFunc("/debug/dump/memory", dumpMemProfile)
-		mux.HandleFunc("/debug/dump/memstats", dumpMemStats)
-
-		server := http.Server{
-			Addr:              ":" + adminPort,
-			Handler:           mux,
-			ReadHeaderTimeout: 60 * time.Second,
-		}
-		//nolint:errcheck
-		go server.ListenAndServe()
+	app.registerDefaultAdminHandlers()
+	if err := app.startAdminServer(config.Admin); err != nil {
+		return nil, err
 	}
 
 	return app, nil
@@ -123,12 +150,65 @@ func MustNew(ctx context.Context, adminPort string) *App {
 	return app
 }
 
-// Shutdown calls all shutdown methods, in order they were added.
-func (a *App) Shutdown(ctx context.Context) (err error) {
+// Shutdown calls all shutdown methods, ordered by priority and dependencies.
+// A report of the execution is logged, exported as metrics and made available
+// through LastShutdownReport. If the leak check is enabled, the goroutines
+// leaked by the shutdown are added to the report and logged with their stacks.
+func (a *App) Shutdown(ctx context.Context) error {
 	log.Trace().Msg("[app] Starting graceful shutdown.")
 
+	report := a.shutdown(ctx)
+	if a.leakCheck.Enabled {
+		log.Trace().Dur("leak_check_timeout", a.leakCheck.Timeout).Msg("[app] Checking for leaked goroutines.")
+		report.LeakedGoroutines = a.goroutineBaseline.checkLeaks(a.leakCheck)
+		leakedGoroutines.Set(float64(len(report.LeakedGoroutines)))
+		if len(report.LeakedGoroutines) > 0 {
+			arr := zerolog.Arr()
+			for _, g := range report.LeakedGoroutines {
+				arr.Object(g)
+			}
+			log.Ctx(ctx).Warn().
+				Int("leaked_goroutines_count", len(report.LeakedGoroutines)).
+				Array("leaked_goroutines", arr).
+				Msg("Goroutines leaked by the shutdown.")
+		}
+	}
+
+	report.observe()
+	log.Ctx(ctx).Info().Object("shutdown_report", report).Msg("Shutdown report.")
+	a.lastShutdownReportMu.Lock()
+	a.lastShutdownReport = &report
+	a.lastShutdownReportMu.Unlock()
+
+	if report.Err != nil {
+		log.Trace().Err(report.Err).Msg("[app] Graceful shutdown failed.")
+		return report.Err
+	}
+
+	log.Trace().Msg("[app] Graceful shutdown finished successfully.")
+	return nil
+}
+
+// shutdown executes the shutdown handlers and stops the probe checks.
+func (a *App) shutdown(ctx context.Context) ShutdownReport {
 	const op = errors.Op("app.App.Shutdown")
 
+	defer a.Startup.StopChecks()
+	defer a.Healthy.StopChecks()
+	defer a.Ready.StopChecks()
+
+	if a.adminShutdownHandler != nil {
+		// Handlers with the same priority run in parallel, so the admin server
+		// must explicitly wait for the others.
+		for _, h := range a.shutdownHandlers {
+			if h.Priority == a.adminShutdownHandler.Priority {
+				a.adminShutdownHandler.DependsOn = append(a.adminShutdownHandler.DependsOn, h.Name)
+			}
+		}
+		a.RegisterShutdownHandler(a.adminShutdownHandler)
+		a.adminShutdownHandler = nil
+	}
+
 	if a.ShutdownTimeout > 0 {
 		log.Trace().Dur("shutdown_timeout", a.ShutdownTimeout).Msg("[app] Configuring a timeout for the shutdown.")
 		var cancel func()
@@ -136,87 +216,150 @@ func (a *App) Shutdown(ctx context.Context) (err error) {
 		defer cancel()
 	}
 
+	handlers := make([]*ShutdownHandler, 0, a.shutdownHandlers.Len())
+	for a.shutdownHandlers.Len() > 0 {
+		handlers = append(handlers, heap.Pop(&a.shutdownHandlers).(*ShutdownHandler))
+	}
+
+	var err error
+	start := time.Now()
 	select {
 	case <-ctx.Done():
 		err = errors.E(op, "shutdown deadline has been reached")
-	case err = <-a.shutdownAllHandlers(ctx):
-	}
-	if err != nil {
-		log.Trace().Err(err).Msg("[app] Graceful shutdown failed.")
-		return errors.E(op, err)
+	case err = <-shutdownAllHandlers(ctx, handlers):
+		err = errors.E(op, err)
 	}
 
-	log.Trace().Msg("[app] Graceful shutdown finished successfully.")
-	return nil
+	return newShutdownReport(handlers, time.Since(start), err)
 }
 
-func (a *App) shutdownAllHandlers(ctx context.Context) chan error {
+// LastShutdownReport returns the report of the last shutdown, or nil if the app was not shut down yet.
+func (a *App) LastShutdownReport() *ShutdownReport {
+	a.lastShutdownReportMu.Lock()
+	defer a.lastShutdownReportMu.Unlock()
+	return a.lastShutdownReport
+}
+
+func shutdownAllHandlers(ctx context.Context, handlers []*ShutdownHandler) chan error {
 	const op = errors.Op("shutdownAllHandlers")
-	done := make(chan error)
+
+	done := make(chan error, 1)
 	go func() {
 		defer close(done)
-		for a.shutdownHandlers.Len() > 0 {
-			h := heap.Pop(&a.shutdownHandlers).(*ShutdownHandler)
+		executed := make(map[string]bool, len(handlers))
+		for _, tier := range shutdownTiers(handlers) {
 			if ctx.Err() != nil {
-				done <- errors.E(op, "shutdow deadline has been reached")
+				done <- errors.E(op, "shutdown deadline has been reached")
+				return
 			}
-			trace := log.Trace().
-				Str("shutdown_handler_name", h.Name).
-				Uint8("shutdown_handler_priority", uint8(h.Priority)).
-				Dur("shutdown_handler_timeout", h.Timeout).
-				Str("shutdown_handler_policy", ErrorPolicyString(h.Policy))
-
-			trace.Msg("[app] Executing shutdown handler.")
-			if err := h.Execute(ctx); err != nil {
-				trace.Msg("[app] Shutdown handler failed.")
+			if err := runShutdownTier(ctx, tier, executed); err != nil {
 				done <- errors.E(op, err)
+				return
 			}
-			trace.Msg("[app] Shutdown handler finished.")
 		}
 	}()
 	return done
 }
 
-// RunAndWait executes the main loop on a go-routine and listens to SIGINT and SIGKILL to start the shutdown
+// RunAndWait executes the main loop on a go-routine and listens to SIGINT and SIGKILL to start the shutdown.
+// While running, a SIGHUP reloads the config with ReloadConfig.
+// The app is set as started and ready right before the main loop is called.
+// Loops registered with Go are started alongside the main loop. The main loop may be nil
+// if there is at least one loop registered with Go.
 func (a *App) RunAndWait(mainLoop MainLoopFunc) {
+	if mainLoop == nil {
+		a.RunAndWaitWithReady(nil)
+		return
+	}
+	a.RunAndWaitWithReady(func(ready func()) error {
+		ready()
+		return mainLoop()
+	})
+}
+
+// RunAndWaitWithReady is like RunAndWait but the app is only set as started
+// and ready when the main loop calls ready. This allows the main loop to warm
+// up caches or subscribe to consumers before receiving traffic.
+func (a *App) RunAndWaitWithReady(mainLoop MainLoopWithReadyFunc) {
 	log.Trace().Msg("[app] Starting run and wait.")
 
-	errs := make(chan error)
+	errs := make(chan error, 1)
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				errs <- errors.NewFromRecover(r)
-			}
-		}()
+	// Once the shutdown starts, ready must not set the app as ready again.
+	var readyOnce sync.Once
+	ready := func() {
+		readyOnce.Do(func() {
+			a.mainStartupProbe.SetOk()
+			a.setReady(true)
+			a.logger.Info().Msg("Application is ready!")
+		})
+	}
 
-		a.logger.Info().Msg("Application main loop starting now!")
-		if mainLoop == nil {
-			errs <- errors.New("main loop is nil")
-			return
-		}
-		a.mainReadinessProbe.SetOk()
-		errs <- mainLoop()
-	}()
+	// Supervised loops are not restarted after the shutdown starts.
+	loopsCtx, stopLoops := context.WithCancel(context.Background())
+	defer stopLoops()
+	loopErrs := make(chan error)
+	a.startLoops(loopsCtx, loopErrs)
 
-	notifyCtx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
+	notReady := func() {
+		readyOnce.Do(func() {})
+		stopLoops()
+		a.setReady(false)
+	}
+
+	switch {
+	case mainLoop != nil:
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					errs <- errors.NewFromRecover(r)
+				}
+			}()
+
+			a.logger.Info().Msg("Application main loop starting now!")
+			errs <- mainLoop(ready)
+		}()
+	case a.hasLoops():
+		// Only supervised loops, there is no main loop to signal readiness
+		ready()
+	default:
+		errs <- errors.New("main loop is nil")
+	}
+
+	signals := a.Signals
+	if signals == nil {
+		osSignals := make(chan os.Signal, 1)
+		signal.Notify(osSignals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(osSignals)
+		signals = osSignals
+	}
 
 	var err error
 	ctx := a.logger.WithContext(context.Background())
-	select {
-	case <-notifyCtx.Done():
-		a.mainReadinessProbe.SetNotOk()
-		a.logger.Info().
-			Dur("grace_period", a.GracePeriod).
-			Msg("Graceful shutdown signal received! Awaiting for grace period to end.")
-		time.Sleep(a.GracePeriod)
-		a.logger.Info().Msg("Grace period is over, initiating shutdown procedures...")
-		err = a.Shutdown(ctx)
-	case err = <-errs:
-		a.mainReadinessProbe.SetNotOk()
-		a.logger.Info().Err(err).Msg("Main Loop finished by itself, initiating shutdown procedures...")
-		err = a.Shutdown(ctx)
+	for {
+		select {
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				a.reloadConfigOnSignal()
+				continue
+			}
+			notReady()
+			a.logger.Info().
+				Dur("grace_period", a.GracePeriod).
+				Msg("Graceful shutdown signal received! Awaiting for grace period to end.")
+			<-a.clock().After(ctx, a.GracePeriod)
+			a.logger.Info().Msg("Grace period is over, initiating shutdown procedures...")
+			err = a.Shutdown(ctx)
+		case err = <-errs:
+			notReady()
+			a.logger.Info().Err(err).Msg("Main Loop finished by itself, initiating shutdown procedures...")
+			err = a.Shutdown(ctx)
+		case err = <-loopErrs:
+			notReady()
+			a.logger.Info().Err(err).Msg("Critical loop finished, initiating shutdown procedures...")
+			err = a.Shutdown(ctx)
+		}
+		break
 	}
 	if err == nil {
 		a.logger.Info().Msg("App gracefully terminated.")
@@ -228,7 +371,9 @@ func (a *App) RunAndWait(mainLoop MainLoopFunc) {
 	a.mainHealthnessProbe.SetNotOk()
 }
 
-// RegisterShutdownHandler adds a handler in the end of the list. During shutdown all handlers are executed in the order they were added
+// RegisterShutdownHandler adds a handler to be executed during shutdown. Handlers are executed from the
+// highest to the lowest priority. Handlers with the same priority are executed in parallel, unless one
+// depends on another through DependsOn.
 func (a *App) RegisterShutdownHandler(sh *ShutdownHandler) {
 	if sh.Name == "" {
 		panic("Shutdown handler name must not be an empty string")
@@ -243,5 +388,6 @@ func (a *App) RegisterShutdownHandler(sh *ShutdownHandler) {
 		Uint8("shutdown_handler_priority", uint8(sh.Priority)).
 		Dur("shutdown_handler_timeout", sh.Timeout).
 		Str("shutdown_handler_policy", ErrorPolicyString(sh.Policy)).
+		Strs("shutdown_handler_depends_on", sh.DependsOn).
 		Msg("[app] Shutdown handler registered")
 }
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAndWaitWithReady(t *testing.T) {
+	a := newTestApp(t)
+
+	warmingUp := make(chan struct{})
+	ready := make(chan struct{})
+	finish := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		a.RunAndWaitWithReady(func(setReady func()) error {
+			close(warmingUp)
+			<-ready
+			setReady()
+			<-finish
+			return nil
+		})
+	}()
+
+	<-warmingUp
+	status, _ := adminGet(t, a, "/startup")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	status, _ = adminGet(t, a, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	close(ready)
+	assert.Eventually(t, func() bool {
+		return a.mainReadinessProbe.IsOk()
+	}, time.Second, time.Millisecond)
+	status, _ = adminGet(t, a, "/startup")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = adminGet(t, a, "/ready")
+	assert.Equal(t, http.StatusOK, status)
+
+	close(finish)
+	<-done
+	assert.False(t, a.mainReadinessProbe.IsOk())
+	assert.False(t, a.mainHealthnessProbe.IsOk())
+	assert.True(t, a.mainStartupProbe.IsOk())
+}
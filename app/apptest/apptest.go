@@ -0,0 +1,314 @@
+/*
+Package apptest provides a harness to test the lifecycle of an app.App
+deterministically: the admin server listens on an ephemeral port, the grace
+period is waited on a FakeClock and the shutdown is triggered by synthetic
+signals instead of OS signals. The test fails if the shutdown leaks
+goroutines, like consumers or tickers that were not stopped.
+
+	func TestLifecycle(t *testing.T) {
+		h := apptest.New(t)
+		h.RegisterShutdownHandler(&app.ShutdownHandler{Name: "server", Priority: 10, Handler: server.Shutdown})
+		h.RegisterShutdownHandler(&app.ShutdownHandler{Name: "database", Handler: db.Close})
+
+		h.Run(server.ListenAndServe)
+		h.WaitReady()
+
+		h.Stop()
+		h.AssertShutdownOrder("server", "database")
+		h.AssertOutcome("database", app.ShutdownOutcomeSuccess)
+	}
+*/
+package apptest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/app"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultTimeout is how long the harness waits for the app before failing the test.
+var DefaultTimeout = 5 * time.Second
+
+// Harness runs an app.App for a test.
+type Harness struct {
+	// App is the app under test. Its fields, like GracePeriod, may be changed before Run.
+	App *app.App
+	// Clock is the clock used by App.
+	Clock *FakeClock
+	// Timeout is how long the harness waits for the app before failing the test.
+	Timeout time.Duration
+
+	t       testing.TB
+	signals chan os.Signal
+	started bool
+	done    chan struct{}
+
+	orderMu sync.Mutex
+	order   []string
+}
+
+// NewDefaultConfig returns the app.Config used by New: the admin server
+// listens on an ephemeral port on the loopback interface and the leak check
+// is enabled.
+func NewDefaultConfig() app.Config {
+	config := app.NewDefaultConfig()
+	config.Admin.Addr = "127.0.0.1:0"
+	config.LeakCheck.Enabled = true
+	return config
+}
+
+// New returns a harness with a new App configured by NewDefaultConfig.
+func New(t testing.TB) *Harness {
+	t.Helper()
+	return NewWithConfig(t, NewDefaultConfig())
+}
+
+// NewWithConfig returns a harness with a new App configured by config,
+// logging to the test log. The app is shutdown when the test finishes, if it
+// wasn't already, and the test fails if the shutdown leaked goroutines.
+// Since goroutines started by other tests running in parallel would be
+// reported as leaked, tests using the leak check must not be parallel.
+func NewWithConfig(t testing.TB, config app.Config) *Harness {
+	t.Helper()
+
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	a, err := app.NewWithConfig(logger.WithContext(context.Background()), config)
+	require.NoError(t, err, "failed to create app")
+
+	h := &Harness{
+		App:     a,
+		Clock:   NewFakeClock(time.Now()),
+		Timeout: DefaultTimeout,
+		t:       t,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	a.Clock = h.Clock
+	a.Signals = h.signals
+	a.ShutdownTimeout = DefaultTimeout
+
+	t.Cleanup(h.cleanup)
+	return h
+}
+
+// Run calls App.RunAndWait with mainLoop on a go-routine.
+func (h *Harness) Run(mainLoop app.MainLoopFunc) {
+	h.t.Helper()
+	if mainLoop == nil {
+		h.RunWithReady(nil)
+		return
+	}
+	h.RunWithReady(func(ready func()) error {
+		ready()
+		return mainLoop()
+	})
+}
+
+// RunWithReady calls App.RunAndWaitWithReady with mainLoop on a go-routine.
+func (h *Harness) RunWithReady(mainLoop app.MainLoopWithReadyFunc) {
+	h.t.Helper()
+	require.False(h.t, h.started, "app already running")
+	h.started = true
+
+	go func() {
+		defer close(h.done)
+		h.App.RunAndWaitWithReady(mainLoop)
+	}()
+}
+
+// Signal sends a synthetic signal to the app, like syscall.SIGTERM to start
+// the graceful shutdown or syscall.SIGHUP to reload the config.
+func (h *Harness) Signal(sig os.Signal) {
+	h.t.Helper()
+	select {
+	case h.signals <- sig:
+	case <-time.After(h.Timeout):
+		h.t.Fatalf("timeout sending signal %v to the app", sig)
+	}
+}
+
+// Stop sends a SIGTERM and advances the clock by the grace period until
+// RunAndWait returns.
+func (h *Harness) Stop() {
+	h.t.Helper()
+	h.Signal(syscall.SIGTERM)
+	if !h.advanceUntilDone() {
+		h.t.Fatal("timeout waiting for the app to finish")
+	}
+}
+
+// Wait waits for RunAndWait to return, like after the main loop finished.
+func (h *Harness) Wait() {
+	h.t.Helper()
+	select {
+	case <-h.done:
+	case <-time.After(h.Timeout):
+		h.t.Fatal("timeout waiting for the app to finish")
+	}
+}
+
+// Done returns a channel closed when RunAndWait returns.
+func (h *Harness) Done() <-chan struct{} {
+	return h.done
+}
+
+// BlockUntilWaiters waits until at least n calls to the clock are waiting
+// for it to advance, like the grace period after a SIGTERM.
+func (h *Harness) BlockUntilWaiters(n int) {
+	h.t.Helper()
+	blocked := make(chan struct{})
+	go func() {
+		h.Clock.BlockUntil(n)
+		close(blocked)
+	}()
+	select {
+	case <-blocked:
+	case <-time.After(h.Timeout):
+		h.t.Fatalf("timeout waiting for %d clock waiters", n)
+	}
+}
+
+// WaitReady waits for the readiness probes to be ok.
+func (h *Harness) WaitReady() {
+	h.t.Helper()
+	require.Eventually(h.t, func() bool {
+		ok, _ := h.App.Ready.CheckProbes()
+		return ok
+	}, h.Timeout, time.Millisecond, "app never became ready")
+}
+
+// AssertReady asserts the state of the readiness probes.
+func (h *Harness) AssertReady(expected bool) bool {
+	h.t.Helper()
+	ok, cause := h.App.Ready.CheckProbes()
+	return assert.Equal(h.t, expected, ok, "readiness: %s", cause)
+}
+
+// AssertHealthy asserts the state of the healthiness probes.
+func (h *Harness) AssertHealthy(expected bool) bool {
+	h.t.Helper()
+	ok, cause := h.App.Healthy.CheckProbes()
+	return assert.Equal(h.t, expected, ok, "healthiness: %s", cause)
+}
+
+// AssertStarted asserts the state of the startup probes.
+func (h *Harness) AssertStarted(expected bool) bool {
+	h.t.Helper()
+	ok, cause := h.App.Startup.CheckProbes()
+	return assert.Equal(h.t, expected, ok, "startup: %s", cause)
+}
+
+// RegisterShutdownHandler registers sh in the app, recording when it starts
+// so the order can be asserted by AssertShutdownOrder.
+func (h *Harness) RegisterShutdownHandler(sh *app.ShutdownHandler) {
+	handler := sh.Handler
+	sh.Handler = func(ctx context.Context) error {
+		h.orderMu.Lock()
+		h.order = append(h.order, sh.Name)
+		h.orderMu.Unlock()
+		return handler(ctx)
+	}
+	h.App.RegisterShutdownHandler(sh)
+}
+
+// ShutdownOrder returns the names of the handlers registered by the harness
+// in the order they started executing.
+func (h *Harness) ShutdownOrder() []string {
+	h.orderMu.Lock()
+	defer h.orderMu.Unlock()
+	return append([]string(nil), h.order...)
+}
+
+// AssertShutdownOrder asserts the order the handlers registered by the harness started executing.
+func (h *Harness) AssertShutdownOrder(names ...string) bool {
+	h.t.Helper()
+	return assert.Equal(h.t, names, h.ShutdownOrder(), "shutdown order")
+}
+
+// Report returns the report of the last shutdown, failing the test if there was none.
+func (h *Harness) Report() *app.ShutdownReport {
+	h.t.Helper()
+	report := h.App.LastShutdownReport()
+	require.NotNil(h.t, report, "app was not shutdown")
+	return report
+}
+
+// AssertOutcome asserts the outcome of a shutdown handler in the last shutdown.
+func (h *Harness) AssertOutcome(name string, expected app.ShutdownOutcome) bool {
+	h.t.Helper()
+	for _, handler := range h.Report().Handlers {
+		if handler.Name == name {
+			return assert.Equal(h.t, expected, handler.Outcome, "outcome of shutdown handler %s", name)
+		}
+	}
+	return assert.Fail(h.t, "shutdown handler not found", name)
+}
+
+// Get requests path on the admin server, returning the status code and body.
+func (h *Harness) Get(path string) (int, string) {
+	h.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "http://"+h.App.AdminAddr().String()+path, nil)
+	require.NoError(h.t, err)
+	// Idle connections would be reported as leaked goroutines
+	req.Close = true
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(h.t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(h.t, err)
+	return resp.StatusCode, string(body)
+}
+
+func (h *Harness) cleanup() {
+	h.stopOnCleanup()
+
+	if report := h.App.LastShutdownReport(); report != nil {
+		for _, g := range report.LeakedGoroutines {
+			h.t.Errorf("goroutine %d leaked by the shutdown [%s], created by %s:\n%s", g.ID, g.State, g.CreatedBy, g.Stack)
+		}
+	}
+}
+
+func (h *Harness) stopOnCleanup() {
+	if !h.started {
+		_ = h.App.Shutdown(context.Background())
+		return
+	}
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+	h.Signal(syscall.SIGTERM)
+	if !h.advanceUntilDone() {
+		h.t.Error("timeout waiting for the app to finish on cleanup")
+	}
+}
+
+// advanceUntilDone advances the clock by the grace period until RunAndWait
+// returns. The grace period may not be awaited yet, and other calls to the
+// clock, like a loop restart backoff, may be waiting as well, so the clock is
+// advanced again until the app finishes. It returns false on timeout.
+func (h *Harness) advanceUntilDone() bool {
+	timeout := time.After(h.Timeout)
+	for {
+		select {
+		case <-h.done:
+			return true
+		case <-timeout:
+			return false
+		case <-time.After(time.Millisecond):
+			h.Clock.Advance(h.App.GracePeriod)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package apptest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/app"
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHarness_Lifecycle(t *testing.T) {
+	h := New(t)
+	h.App.GracePeriod = time.Minute
+
+	h.RegisterShutdownHandler(&app.ShutdownHandler{
+		Name:     "server",
+		Priority: 10,
+		Handler:  func(context.Context) error { return nil },
+	})
+	h.RegisterShutdownHandler(&app.ShutdownHandler{
+		Name:    "database",
+		Policy:  app.ErrorPolicyWarn,
+		Handler: func(context.Context) error { return errors.New("my error") },
+	})
+
+	stop := make(chan struct{})
+	h.AssertStarted(false)
+	h.AssertReady(false)
+	h.RunWithReady(func(ready func()) error {
+		ready()
+		<-stop
+		return nil
+	})
+	h.WaitReady()
+	h.AssertStarted(true)
+	h.AssertHealthy(true)
+
+	status, _ := h.Get("/ready")
+	assert.Equal(t, http.StatusOK, status)
+
+	// During the grace period the app is not ready but still serving
+	h.Signal(syscall.SIGTERM)
+	h.BlockUntilWaiters(1)
+	h.AssertReady(false)
+	status, _ = h.Get("/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	select {
+	case <-h.Done():
+		t.Fatal("the app must wait for the grace period")
+	default:
+	}
+
+	h.Clock.Advance(time.Minute)
+	close(stop)
+	h.Wait()
+
+	h.AssertHealthy(false)
+	h.AssertShutdownOrder("server", "database")
+	h.AssertOutcome("server", app.ShutdownOutcomeSuccess)
+	h.AssertOutcome("database", app.ShutdownOutcomeFailed)
+}
+
+func TestHarness_MainLoopFinished(t *testing.T) {
+	h := New(t)
+	h.Run(func() error { return errors.New("main loop error") })
+	h.Wait()
+
+	h.AssertReady(false)
+	h.AssertHealthy(false)
+	assert.NoError(t, h.Report().Err)
+}
+
+func TestHarness_ReloadSignal(t *testing.T) {
+	h := New(t)
+	stop := make(chan struct{})
+	h.RegisterShutdownHandler(&app.ShutdownHandler{
+		Name:    "main",
+		Handler: func(context.Context) error { close(stop); return nil },
+	})
+	h.Run(func() error { <-stop; return nil })
+	h.WaitReady()
+
+	h.Signal(syscall.SIGHUP)
+	h.Signal(syscall.SIGHUP)
+	h.AssertReady(true)
+
+	h.Stop()
+	h.AssertHealthy(false)
+}
+
+func TestHarness_StopDuringLoopBackoff(t *testing.T) {
+	h := New(t)
+	h.App.GracePeriod = time.Minute
+
+	failed := make(chan struct{}, 1)
+	h.App.Go("flaky", func() error {
+		select {
+		case failed <- struct{}{}:
+		default:
+		}
+		return errors.New("flaky failure")
+	}, app.RestartPolicy{Mode: app.RestartOnFailure, Backoff: time.Second})
+
+	stop := make(chan struct{})
+	h.RegisterShutdownHandler(&app.ShutdownHandler{
+		Name:    "main",
+		Handler: func(context.Context) error { close(stop); return nil },
+	})
+	h.Run(func() error {
+		<-stop
+		return nil
+	})
+	<-failed
+	// The loop is waiting for the restart backoff, not the grace period
+	h.BlockUntilWaiters(1)
+
+	h.Stop()
+	h.AssertOutcome("main", app.ShutdownOutcomeSuccess)
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Now()
+	c := NewFakeClock(start)
+
+	ctx := context.Background()
+	assert.Len(t, c.After(ctx, 0), 1, "non positive durations must fire immediately")
+
+	short := c.After(ctx, time.Second)
+	long := c.After(ctx, time.Minute)
+	canceledCtx, cancel := context.WithCancel(ctx)
+	c.After(canceledCtx, time.Second)
+	assert.Equal(t, 3, c.Waiters())
+	cancel()
+	assert.Equal(t, 2, c.Waiters(), "canceled waits must be dropped")
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-short)
+	assert.Len(t, long, 0)
+	assert.Equal(t, 1, c.Waiters())
+
+	c.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour+time.Second), <-long)
+	assert.Equal(t, start.Add(time.Hour+time.Second), c.Now())
+}
+
+// errorRecorder records the errors reported by the harness.
+type errorRecorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestHarness_LeakedGoroutine(t *testing.T) {
+	leak := make(chan struct{})
+	recorder := &errorRecorder{TB: t}
+	// Cleanups run in reverse order, so this runs after the harness cleanup
+	t.Cleanup(func() {
+		close(leak)
+		require.Len(t, recorder.errors, 1)
+		assert.Contains(t, recorder.errors[0], "leaked by the shutdown [chan receive], created by github.com/arquivei/foundationkit/app/apptest.TestHarness_LeakedGoroutine")
+	})
+
+	config := NewDefaultConfig()
+	config.LeakCheck.Timeout = 10 * time.Millisecond
+	h := NewWithConfig(recorder, config)
+	h.Run(func() error {
+		go func() { <-leak }()
+		return nil
+	})
+	h.Wait()
+}
@@ -0,0 +1,101 @@
+package apptest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FakeClock is an app.Clock that only moves when Advance is called.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	ctx      context.Context
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock returns a FakeClock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the current time once the clock is
+// advanced by d. Non positive durations fire immediately. The wait is
+// forgotten when ctx is canceled.
+func (c *FakeClock) After(ctx context.Context, d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{ctx: ctx, deadline: c.now.Add(d), ch: ch})
+	c.cond.Broadcast()
+	return ch
+}
+
+// Advance moves the clock forward by d, firing the waiters whose deadline was reached.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.dropCanceled()
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// Waiters returns how many calls to After are waiting for the clock to
+// advance, not counting the ones whose context was canceled.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropCanceled()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until at least n calls to After are waiting for the
+// clock to advance, not counting the ones whose context was canceled.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.dropCanceled(); len(c.waiters) < n; c.dropCanceled() {
+		c.cond.Wait()
+	}
+}
+
+// dropCanceled forgets the waiters whose context was canceled, since nobody
+// is waiting for them anymore. It must be called with the lock held.
+func (c *FakeClock) dropCanceled() {
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.ctx.Err() == nil {
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// BuildInfo is the build metadata embedded by the go toolchain in the binary.
+type BuildInfo struct {
+	// Path is the path of the main module.
+	Path string `json:"path"`
+	// Version is the version of the main module. It's "(devel)" for binaries
+	// not built by go install with a version.
+	Version string `json:"version"`
+	// GoVersion is the version of the go toolchain that built the binary.
+	GoVersion string `json:"go_version"`
+	// Revision is the VCS revision the binary was built from.
+	Revision string `json:"revision,omitempty"`
+	// Time is the time of the VCS revision, in RFC3339.
+	Time string `json:"time,omitempty"`
+	// Dirty tells if the working tree had uncommitted changes.
+	Dirty bool `json:"dirty"`
+	// Modules are the dependencies of the main module.
+	Modules []ModuleInfo `json:"modules,omitempty"`
+}
+
+// ModuleInfo is a module the binary was built with.
+type ModuleInfo struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Replace is the module replacing this one, if any.
+	Replace *ModuleInfo `json:"replace,omitempty"`
+}
+
+var buildInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "fkit",
+	Subsystem: "app",
+	Name:      "build_info",
+	Help:      "Always 1, labeled with the build metadata of the binary.",
+}, []string{"path", "version", "go_version", "revision", "time", "dirty"})
+
+// ReadBuildInfo returns the build metadata of the running binary. Fields are
+// left empty if the binary was built without build information.
+func ReadBuildInfo() BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{}
+	}
+
+	bi := BuildInfo{
+		Path:      info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			bi.Revision = s.Value
+		case "vcs.time":
+			bi.Time = s.Value
+		case "vcs.modified":
+			bi.Dirty = s.Value == "true"
+		}
+	}
+	for _, dep := range info.Deps {
+		bi.Modules = append(bi.Modules, newModuleInfo(dep))
+	}
+	return bi
+}
+
+func newModuleInfo(m *debug.Module) ModuleInfo {
+	mi := ModuleInfo{
+		Path:    m.Path,
+		Version: m.Version,
+	}
+	if m.Replace != nil {
+		replace := newModuleInfo(m.Replace)
+		mi.Replace = &replace
+	}
+	return mi
+}
+
+// observe sets the build_info metric. Dependencies are not used as labels
+// to keep the cardinality low, they are only served by the /version endpoint.
+func (bi BuildInfo) observe() {
+	buildInfo.WithLabelValues(
+		bi.Path,
+		bi.Version,
+		bi.GoVersion,
+		bi.Revision,
+		bi.Time,
+		strconv.FormatBool(bi.Dirty),
+	).Set(1)
+}
+
+// newBuildInfoHandler returns a handler that replies with bi as JSON.
+func newBuildInfoHandler(bi BuildInfo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(bi)
+	})
+}
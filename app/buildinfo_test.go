@@ -0,0 +1,34 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBuildInfo(t *testing.T) {
+	bi := ReadBuildInfo()
+	assert.Equal(t, runtime.Version(), bi.GoVersion)
+	assert.NotEmpty(t, bi.Modules)
+}
+
+func TestBuildInfoEndpoint(t *testing.T) {
+	a := newTestApp(t)
+
+	status, body := adminGet(t, a, "/version")
+	assert.Equal(t, http.StatusOK, status)
+
+	var bi BuildInfo
+	require.NoError(t, json.Unmarshal([]byte(body), &bi))
+	assert.Equal(t, ReadBuildInfo(), bi)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(buildInfo.WithLabelValues(
+		bi.Path, bi.Version, bi.GoVersion, bi.Revision, bi.Time, strconv.FormatBool(bi.Dirty),
+	)))
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and waits for durations. The App uses it to wait for
+// the grace period and the restart backoff of supervised loops, so tests can
+// replace it by a fake one.
+type Clock interface {
+	Now() time.Time
+	// After returns a channel that receives the time after d. The wait is
+	// given up when ctx is canceled, so fake clocks can forget it.
+	After(ctx context.Context, d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+func (realClock) After(_ context.Context, d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// clock returns the Clock of the app or the real one if not set.
+func (a *App) clock() Clock {
+	if a.Clock == nil {
+		return realClock{}
+	}
+	return a.Clock
+}
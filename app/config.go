@@ -2,47 +2,235 @@ package app
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/BurntSushi/toml"
+	"github.com/arquivei/foundationkit/errors"
 	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
 	"github.com/omeid/uconfig/plugins/file"
+	"github.com/omeid/uconfig/plugins/flag"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
 )
 
-// ConfigFilename is the filename of the config file automatically loaded by SetupConfig
+// ConfigFilename is the filename of the base config file automatically loaded by SetupConfig.
+// If it doesn't exist, the same name with any other supported extension is tried.
+// If its extension is not supported, like config.conf, it's parsed as JSON.
 var ConfigFilename = "config.json"
 
+// ConfigProfileEnvVar is the environment variable holding the name of the
+// environment profile. If set, SetupConfig also loads the profile config file
+// named after the base config file, like config.<profile>.yaml.
+var ConfigProfileEnvVar = "APP_ENV"
+
+// configFlag is the flag used to pass explicit config files.
+const configFlag = "config"
+
+// configUnmarshalers maps the supported config file extensions to their unmarshal functions.
+var configUnmarshalers = map[string]file.Unmarshal{
+	".json": json.Unmarshal,
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+	".toml": toml.Unmarshal,
+}
+
+// configExtensions is the order the extensions are tried when looking for a config file.
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
 // SetupConfig loads the configuration in the given struct. In case of error, prints help and exit application.
+//
+// The sources are loaded in the following order, each one overriding the previous:
+//  1. Default values from the `default` struct tags.
+//  2. The base config file (ConfigFilename), if it exists.
+//  3. The environment profile config file (like config.<profile>.yaml), if ConfigProfileEnvVar is set and the file exists.
+//  4. The files given by the -config flag, in the order they were given. They must exist.
+//  5. Environment variables.
+//  6. Command line flags.
+//
+// Files can be JSON, YAML or TOML and the format is chosen by the file extension.
+//
+// After loading, the config is validated by ValidateConfig and the application
+// exits reporting all violations if it's invalid.
+//
+// The config can be reloaded later by ReloadConfig. See OnConfigChange.
 func SetupConfig(config interface{}) {
+	c, err := loadConfig(config, os.Args[1:])
+	if err != nil {
+		if c != nil {
+			c.Usage()
+		}
+		if isErrHelpRequested(err) {
+			os.Exit(0)
+		}
+		log.Fatal().Err(err).Msg("Failed to setup config")
+	}
+	if err := ValidateConfig(config); err != nil {
+		log.Fatal().Err(err).Msg("Invalid config")
+	}
+	sources, err := configValueSources(config, os.Args[1:])
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to setup config")
+	}
+	setLoadedConfig(config, sources)
+}
+
+// loadConfig loads all config sources into config using the given command line args.
+func loadConfig(config interface{}, args []string) (uconfig.Config, error) {
+	const op = errors.Op("app.loadConfig")
+
+	layers, err := configLayers(args)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	return parseConfigLayers(config, layers)
+}
+
+// configLayer is a config source plugin and the source of its values.
+type configLayer struct {
+	source ConfigSource
+	file   string
+	plugin plugins.Plugin
+}
+
+// configLayers returns the config plugins, from the lowest to the highest precedence.
+// Plugins can't be reused, so a new set must be created for each load.
+func configLayers(args []string) ([]configLayer, error) {
+	configFiles, args, err := extractConfigFiles(args)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := configSources(configFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]configLayer, 0, len(files)+3)
+	layers = append(layers, configLayer{source: ConfigSourceDefault, plugin: defaults.New()})
+	for i, p := range files.Plugins() {
+		layers = append(layers, configLayer{source: ConfigSourceFile, file: files[i].Path, plugin: p})
+	}
+	layers = append(layers,
+		configLayer{source: ConfigSourceEnv, plugin: env.New()},
+		configLayer{source: ConfigSourceFlag, plugin: flag.New(os.Args[0], flag.ContinueOnError, args)},
+	)
+	return layers, nil
+}
+
+func parseConfigLayers(config interface{}, layers []configLayer) (uconfig.Config, error) {
+	ps := make([]plugins.Plugin, 0, len(layers))
+	for _, l := range layers {
+		ps = append(ps, l.plugin)
+	}
+
+	c, err := uconfig.New(config, ps...)
+	if err != nil {
+		return c, err
+	}
+	return c, c.Parse()
+}
+
+// configSources returns the config files to be loaded, from the lowest to the highest precedence.
+func configSources(explicit []string) (uconfig.Files, error) {
 	var files uconfig.Files
-	if fileExists(ConfigFilename) {
-		files = []struct {
+	add := func(path string, optional bool) error {
+		unmarshal, ok := configUnmarshalers[strings.ToLower(filepath.Ext(path))]
+		if !ok && path == ConfigFilename {
+			// The base config file was always parsed as JSON before other
+			// formats were supported.
+			unmarshal, ok = json.Unmarshal, true
+		}
+		if !ok {
+			return errors.Errorf("unsupported config file extension: %s", path)
+		}
+		files = append(files, struct {
 			Path      string
 			Unmarshal file.Unmarshal
 			Optional  bool
 		}{
-			{
-				Path:      ConfigFilename,
-				Unmarshal: json.Unmarshal,
-				Optional:  true,
-			},
+			Path:      path,
+			Unmarshal: unmarshal,
+			Optional:  optional,
+		})
+		return nil
+	}
+
+	if base, ok := findConfigFile(strings.TrimSuffix(ConfigFilename, filepath.Ext(ConfigFilename)), ConfigFilename); ok {
+		if err := add(base, true); err != nil {
+			return nil, err
 		}
 	}
 
-	c, err := uconfig.Classic(config, files)
-	if err != nil {
-		if c != nil {
-			c.Usage()
+	if profile := os.Getenv(ConfigProfileEnvVar); profile != "" {
+		stem := strings.TrimSuffix(ConfigFilename, filepath.Ext(ConfigFilename)) + "." + profile
+		if path, ok := findConfigFile(stem, ""); ok {
+			if err := add(path, true); err != nil {
+				return nil, err
+			}
 		}
-		if isErrHelpRequested(err) {
-			os.Exit(0)
+	}
+
+	for _, path := range explicit {
+		if err := add(path, false); err != nil {
+			return nil, err
 		}
-		log.Fatal().Err(err).Msg("Failed to setup config")
 	}
+
+	return files, nil
+}
+
+// findConfigFile returns the first existing file among preferred and stem
+// with any of the supported extensions.
+func findConfigFile(stem string, preferred string) (string, bool) {
+	if preferred != "" && fileExists(preferred) {
+		return preferred, true
+	}
+	for _, ext := range configExtensions {
+		if path := stem + ext; fileExists(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// extractConfigFiles removes the -config flags from args, returning their values
+// and the remaining args. Both -config and --config are accepted, either as
+// "-config path" or "-config=path", and may be repeated.
+func extractConfigFiles(args []string) (files []string, remaining []string, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			remaining = append(remaining, args[i:]...)
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if !strings.HasPrefix(arg, "-") || (name != configFlag && !strings.HasPrefix(name, configFlag+"=")) {
+			remaining = append(remaining, arg)
+			continue
+		}
+
+		if value, ok := strings.CutPrefix(name, configFlag+"="); ok {
+			files = append(files, value)
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, nil, errors.New("flag needs an argument: -" + configFlag)
+		}
+		i++
+		files = append(files, args[i])
+	}
+	return files, remaining, nil
 }
 
 func isErrHelpRequested(err error) bool {
-	return err != nil && err.Error() == "flag: help requested"
+	return err != nil && (stderrors.Is(err, uconfig.ErrUsage) || err.Error() == "flag: help requested")
 }
 
 func fileExists(path string) bool {
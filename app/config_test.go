@@ -0,0 +1,113 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Base    string `default:"default"`
+	Profile string `default:"default"`
+	File    string `default:"default"`
+	Env     string `default:"default"`
+	Flag    string `default:"default"`
+	Nested  struct {
+		Value int `default:"1"`
+	}
+}
+
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func setConfigFilename(t *testing.T, filename string) {
+	t.Helper()
+	old := ConfigFilename
+	ConfigFilename = filename
+	t.Cleanup(func() { ConfigFilename = old })
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	setConfigFilename(t, filepath.Join(dir, "config.json"))
+
+	writeConfigFile(t, dir, "config.json",
+		`{"Base":"base","Profile":"base","File":"base","Env":"base","Flag":"base"}`)
+	writeConfigFile(t, dir, "config.staging.yaml",
+		"profile: profile\nfile: profile\nenv: profile\nflag: profile\n")
+	explicit := writeConfigFile(t, dir, "explicit.toml",
+		"File = \"file\"\nEnv = \"file\"\nFlag = \"file\"\n[Nested]\nValue = 42\n")
+
+	t.Setenv(ConfigProfileEnvVar, "staging")
+	t.Setenv("ENV", "env")
+	t.Setenv("FLAG", "env")
+
+	var config testConfig
+	_, err := loadConfig(&config, []string{"-flag", "flag", "--config=" + explicit})
+	require.NoError(t, err)
+
+	assert.Equal(t, "base", config.Base)
+	assert.Equal(t, "profile", config.Profile)
+	assert.Equal(t, "file", config.File)
+	assert.Equal(t, "env", config.Env)
+	assert.Equal(t, "flag", config.Flag)
+	assert.Equal(t, 42, config.Nested.Value)
+}
+
+func TestLoadConfig_BaseWithOtherExtension(t *testing.T) {
+	dir := t.TempDir()
+	setConfigFilename(t, filepath.Join(dir, "config.json"))
+	writeConfigFile(t, dir, "config.toml", "Base = \"toml\"\n")
+
+	var config testConfig
+	_, err := loadConfig(&config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "toml", config.Base)
+	assert.Equal(t, "default", config.Profile)
+}
+
+func TestLoadConfig_BaseWithUnknownExtension(t *testing.T) {
+	dir := t.TempDir()
+	setConfigFilename(t, writeConfigFile(t, dir, "app.conf", `{"Base":"conf"}`))
+
+	var config testConfig
+	_, err := loadConfig(&config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "conf", config.Base, "the base config file must be parsed as JSON")
+
+	setConfigFilename(t, writeConfigFile(t, dir, "settings", `{"Base":"no extension"}`))
+	_, err = loadConfig(&config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "no extension", config.Base)
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	setConfigFilename(t, filepath.Join(dir, "config.json"))
+
+	var config testConfig
+	_, err := loadConfig(&config, []string{"-config", filepath.Join(dir, "missing.yaml")})
+	assert.Error(t, err, "explicit config files must exist")
+
+	_, err = loadConfig(&config, []string{"-config", writeConfigFile(t, dir, "config.ini", "")})
+	assert.ErrorContains(t, err, "unsupported config file extension")
+
+	_, err = loadConfig(&config, []string{"-config"})
+	assert.EqualError(t, err, "app.loadConfig: flag needs an argument: -config")
+}
+
+func TestExtractConfigFiles(t *testing.T) {
+	files, remaining, err := extractConfigFiles([]string{
+		"-config", "a.yaml", "-log-level=debug", "--config=b.toml", "-configuration", "x", "--", "-config", "c.json",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.yaml", "b.toml"}, files)
+	assert.Equal(t, []string{"-log-level=debug", "-configuration", "x", "--", "-config", "c.json"}, remaining)
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/arquivei/foundationkit/log"
+)
+
+// ConfigSource is the source that set a config value.
+type ConfigSource string
+
+const (
+	// ConfigSourceDefault is a value from the `default` struct tag, or the zero value.
+	ConfigSourceDefault ConfigSource = "default"
+	// ConfigSourceFile is a value from a config file.
+	ConfigSourceFile ConfigSource = "file"
+	// ConfigSourceEnv is a value from an environment variable.
+	ConfigSourceEnv ConfigSource = "env"
+	// ConfigSourceFlag is a value from a command line flag.
+	ConfigSourceFlag ConfigSource = "flag"
+)
+
+// ConfigValue is a value of the effective config and where it came from.
+type ConfigValue struct {
+	// Value is the formatted value, or log.Redacted for secret fields.
+	Value string `json:"value"`
+	// Source is the source that set the value.
+	Source ConfigSource `json:"source"`
+	// File is the path of the config file when Source is ConfigSourceFile.
+	File string `json:"file,omitempty"`
+}
+
+// configOrigin is where the value of a field came from.
+type configOrigin struct {
+	source ConfigSource
+	file   string
+}
+
+// EffectiveConfig returns the config applied by SetupConfig and the later
+// reloads, flattened like log.Flatten. Fields tagged with `secret:"true"` have
+// their values replaced by log.Redacted. It returns nil if SetupConfig wasn't called.
+func EffectiveConfig() map[string]ConfigValue {
+	loadedConfig.reloadMu.Lock()
+	defer loadedConfig.reloadMu.Unlock()
+
+	if !loadedConfig.current.IsValid() {
+		return nil
+	}
+
+	values := log.FlattenMap(loadedConfig.current.Interface())
+	effective := make(map[string]ConfigValue, len(values))
+	for key, value := range values {
+		origin := findConfigOrigin(loadedConfig.origins, key)
+		effective[key] = ConfigValue{
+			Value:  fmt.Sprint(value),
+			Source: origin.source,
+			File:   origin.file,
+		}
+	}
+	return effective
+}
+
+// findConfigOrigin returns the origin of the field holding the flattened key.
+// Keys inside maps or slices are longer than their field paths.
+func findConfigOrigin(origins map[string]configOrigin, key string) configOrigin {
+	for {
+		if origin, ok := origins[key]; ok {
+			return origin
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return configOrigin{source: ConfigSourceDefault}
+		}
+		key = key[:i]
+	}
+}
+
+// configValueSources finds the source of each field by loading config again
+// adding one layer at a time and checking which fields each layer changed.
+// A layer setting a field to the value it already had is not considered.
+func configValueSources(config interface{}, args []string) (map[string]configOrigin, error) {
+	const op = errors.Op("app.configValueSources")
+
+	layers, err := configLayers(args)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	t := reflect.TypeOf(config).Elem()
+	origins := make(map[string]configOrigin)
+	previous := reflect.New(t)
+	for i, layer := range layers {
+		// Plugins can't be reused, so each load needs new layers.
+		fresh, err := configLayers(args)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		current := reflect.New(t)
+		if _, err := parseConfigLayers(current.Interface(), fresh[:i+1]); err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		diffConfig(previous.Elem(), current.Elem(), "", false, func(field string, _ bool, _, _ reflect.Value) {
+			origins[field] = configOrigin{source: layer.source, file: layer.file}
+		})
+		previous = current
+	}
+
+	return origins, nil
+}
+
+// newEffectiveConfigHandler returns a handler that replies with the effective config as JSON.
+func newEffectiveConfigHandler(effectiveConfig func() map[string]ConfigValue) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		config := effectiveConfig()
+		if config == nil {
+			http.Error(w, "config was not set up by SetupConfig", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(config)
+	})
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/arquivei/foundationkit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := writeConfigFile(t, dir, "config.json", `{"Config":{"File":"file","Flag":"file"},"Labels":{"team":"core"}}`)
+	t.Setenv("CONFIG_ENV", "env")
+	t.Setenv("PASSWORD", "q1w2e3")
+
+	var config struct {
+		Config   testConfig
+		Password string `secret:"true"`
+		Labels   map[string]string
+	}
+	setConfigFilename(t, file)
+	setupReloadTest(t, &config, "-config-flag", "flag")
+
+	effective := EffectiveConfig()
+	assert.Equal(t, ConfigValue{Value: "default", Source: ConfigSourceDefault}, effective["Config.Base"])
+	assert.Equal(t, ConfigValue{Value: "file", Source: ConfigSourceFile, File: file}, effective["Config.File"])
+	assert.Equal(t, ConfigValue{Value: "env", Source: ConfigSourceEnv}, effective["Config.Env"])
+	assert.Equal(t, ConfigValue{Value: "flag", Source: ConfigSourceFlag}, effective["Config.Flag"])
+	assert.Equal(t, ConfigValue{Value: "1", Source: ConfigSourceDefault}, effective["Config.Nested.Value"])
+	assert.Equal(t, ConfigValue{Value: log.Redacted, Source: ConfigSourceEnv}, effective["Password"])
+	assert.Equal(t, ConfigValue{Value: "core", Source: ConfigSourceFile, File: file}, effective["Labels.team"])
+}
+
+func TestEffectiveConfigHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	newEffectiveConfigHandler(func() map[string]ConfigValue { return nil }).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	newEffectiveConfigHandler(func() map[string]ConfigValue {
+		return map[string]ConfigValue{
+			"Log.Level": {Value: "debug", Source: ConfigSourceFile, File: filepath.Join("etc", "config.yaml")},
+		}
+	}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body map[string]map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]map[string]string{
+		"Log.Level": {"value": "debug", "source": "file", "file": filepath.Join("etc", "config.yaml")},
+	}, body)
+}
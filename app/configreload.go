@@ -0,0 +1,254 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ConfigChange describes a reloadable config field that changed on a reload.
+type ConfigChange struct {
+	// Field is the path to the field, like Log.Level.
+	Field string
+	// Old is the value before the reload.
+	Old interface{}
+	// New is the value after the reload.
+	New interface{}
+}
+
+type configChangeCallback struct {
+	field    string
+	callback func(ConfigChange)
+}
+
+// loadedConfig holds the config applied by SetupConfig and the later reloads.
+var loadedConfig struct {
+	// reloadMu serializes reloads.
+	reloadMu sync.Mutex
+	// current is a pointer to a private copy of the applied config.
+	current reflect.Value
+	// origins has the source of each field of current.
+	origins map[string]configOrigin
+
+	callbacksMu sync.Mutex
+	callbacks   []configChangeCallback
+}
+
+// OnConfigChange registers a callback to be called when a reloadable field
+// changes on a config reload. The field is the path to the field, like
+// Log.Level, and a struct path, like Log, receives the changes of all its
+// fields. An empty field receives all changes.
+//
+// Fields are reloadable if they, or any struct containing them, have the
+// `reload:"true"` tag. Changes to other fields are ignored with a warning,
+// since they require a restart.
+func OnConfigChange(field string, callback func(ConfigChange)) {
+	if callback == nil {
+		panic("config change callback must not be nil")
+	}
+	loadedConfig.callbacksMu.Lock()
+	defer loadedConfig.callbacksMu.Unlock()
+	loadedConfig.callbacks = append(loadedConfig.callbacks, configChangeCallback{
+		field:    field,
+		callback: callback,
+	})
+}
+
+// ReloadConfig reloads the config set up by SetupConfig from all sources,
+// validates it and calls the OnConfigChange callbacks for each reloadable
+// field that changed. The struct given to SetupConfig is not modified, the
+// new values are only delivered to the callbacks.
+//
+// The App calls ReloadConfig when it receives a SIGHUP or a POST request on
+// the /debug/config/reload admin endpoint. If the new config can't be loaded
+// or is invalid, an error is returned and nothing changes.
+func ReloadConfig() ([]ConfigChange, error) {
+	return reloadConfig(os.Args[1:])
+}
+
+// reloadConfigOnSignal reloads the config and logs the failure, if any.
+func (a *App) reloadConfigOnSignal() {
+	a.logger.Info().Msg("Reload signal received, reloading config...")
+	if _, err := ReloadConfig(); err != nil {
+		a.logger.Error().Err(err).Msg("Failed to reload config.")
+	}
+}
+
+// newConfigReloadHandler returns a handler that reloads the config on POST
+// requests and replies with the changed fields.
+func newConfigReloadHandler(reload func() ([]ConfigChange, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		changes, err := reload()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Changed []string `json:"changed"`
+		}{
+			Changed: configChangeFields(changes),
+		})
+	})
+}
+
+// setLoadedConfig stores a copy of config as the applied config.
+func setLoadedConfig(config interface{}, origins map[string]configOrigin) {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	current := reflect.New(v.Elem().Type())
+	current.Elem().Set(v.Elem())
+
+	loadedConfig.reloadMu.Lock()
+	defer loadedConfig.reloadMu.Unlock()
+	loadedConfig.current = current
+	loadedConfig.origins = origins
+}
+
+func reloadConfig(args []string) ([]ConfigChange, error) {
+	const op = errors.Op("app.ReloadConfig")
+
+	loadedConfig.reloadMu.Lock()
+	defer loadedConfig.reloadMu.Unlock()
+
+	if !loadedConfig.current.IsValid() {
+		return nil, errors.E(op, "config was not set up by SetupConfig")
+	}
+
+	next := reflect.New(loadedConfig.current.Elem().Type())
+	if _, err := loadConfig(next.Interface(), args); err != nil {
+		return nil, errors.E(op, err)
+	}
+	if err := ValidateConfig(next.Interface()); err != nil {
+		return nil, errors.E(op, err)
+	}
+	origins, err := configValueSources(next.Interface(), args)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	merged := reflect.New(next.Elem().Type())
+	merged.Elem().Set(loadedConfig.current.Elem())
+
+	var changes []ConfigChange
+	var ignored []string
+	diffConfig(merged.Elem(), next.Elem(), "", false, func(field string, reloadable bool, applied, loaded reflect.Value) {
+		if !reloadable {
+			ignored = append(ignored, field)
+			if origin, ok := loadedConfig.origins[field]; ok {
+				origins[field] = origin
+			} else {
+				delete(origins, field)
+			}
+			return
+		}
+		changes = append(changes, ConfigChange{
+			Field: field,
+			Old:   applied.Interface(),
+			New:   loaded.Interface(),
+		})
+		applied.Set(loaded)
+	})
+	loadedConfig.current = merged
+	loadedConfig.origins = origins
+
+	if len(ignored) > 0 {
+		log.Warn().
+			Strs("fields", ignored).
+			Msg("[app] Config fields changed but are not reloadable, a restart is required to apply them.")
+	}
+	log.Info().
+		Strs("fields", configChangeFields(changes)).
+		Msg("[app] Config reloaded.")
+
+	for _, c := range changes {
+		notifyConfigChange(c)
+	}
+
+	return changes, nil
+}
+
+// diffConfig walks applied and loaded in parallel calling changed for
+// every field whose value differ. Structs with exported fields are walked
+// field by field, any other value is compared as a whole.
+func diffConfig(
+	applied, loaded reflect.Value,
+	path string,
+	reloadable bool,
+	changed func(field string, reloadable bool, applied, loaded reflect.Value),
+) {
+	if applied.Kind() == reflect.Struct && hasExportedFields(applied.Type()) {
+		t := applied.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			diffConfig(
+				applied.Field(i),
+				loaded.Field(i),
+				fieldPath,
+				reloadable || field.Tag.Get("reload") == "true",
+				changed,
+			)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(applied.Interface(), loaded.Interface()) {
+		changed(path, reloadable, applied, loaded)
+	}
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func notifyConfigChange(c ConfigChange) {
+	loadedConfig.callbacksMu.Lock()
+	callbacks := make([]configChangeCallback, len(loadedConfig.callbacks))
+	copy(callbacks, loadedConfig.callbacks)
+	loadedConfig.callbacksMu.Unlock()
+
+	for _, cb := range callbacks {
+		if cb.field != "" && cb.field != c.Field && !strings.HasPrefix(c.Field, cb.field+".") {
+			continue
+		}
+		err := errors.DontPanic(func() { cb.callback(c) })
+		if err != nil {
+			log.Error().Err(err).Str("field", c.Field).Msg("[app] Config change callback panicked.")
+		}
+	}
+}
+
+func configChangeFields(changes []ConfigChange) []string {
+	fields := make([]string, 0, len(changes))
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	return fields
+}
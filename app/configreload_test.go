@@ -0,0 +1,113 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testReloadableConfig struct {
+	Name string `default:"app"`
+	Log  struct {
+		Level string `default:"info" fkvalidate:"oneof=debug info warn"`
+		Human bool
+	} `reload:"true"`
+	Timeout time.Duration `default:"1s" reload:"true" fkvalidate:"duration>0"`
+}
+
+// setupReloadTest loads config like SetupConfig does, using the given args.
+func setupReloadTest(t *testing.T, config interface{}, args ...string) {
+	t.Helper()
+
+	_, err := loadConfig(config, args)
+	require.NoError(t, err)
+	origins, err := configValueSources(config, args)
+	require.NoError(t, err)
+	setLoadedConfig(config, origins)
+
+	oldCallbacks := loadedConfig.callbacks
+	t.Cleanup(func() {
+		loadedConfig.current = reflect.Value{}
+		loadedConfig.origins = nil
+		loadedConfig.callbacks = oldCallbacks
+	})
+}
+
+func TestReloadConfig(t *testing.T) {
+	var config testReloadableConfig
+	setConfigFilename(t, filepath.Join(t.TempDir(), "config.json"))
+	setupReloadTest(t, &config)
+
+	var logChanges, allChanges []ConfigChange
+	OnConfigChange("Log", func(c ConfigChange) { logChanges = append(logChanges, c) })
+	OnConfigChange("", func(c ConfigChange) { allChanges = append(allChanges, c) })
+	OnConfigChange("Timeout", func(c ConfigChange) { panic("callbacks must not break the reload") })
+
+	changes, err := reloadConfig(nil)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	t.Setenv("NAME", "renamed")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("TIMEOUT", "5s")
+
+	changes, err = reloadConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigChange{
+		{Field: "Log.Level", Old: "info", New: "debug"},
+		{Field: "Timeout", Old: time.Second, New: 5 * time.Second},
+	}, changes)
+	assert.Equal(t, changes[:1], logChanges)
+	assert.Equal(t, changes, allChanges)
+	assert.Equal(t, "info", config.Log.Level, "the original config must not be modified")
+
+	// Applied changes are not reported again
+	changes, err = reloadConfig(nil)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestReloadConfig_Invalid(t *testing.T) {
+	var config testReloadableConfig
+	setConfigFilename(t, filepath.Join(t.TempDir(), "config.json"))
+	setupReloadTest(t, &config)
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	_, err := reloadConfig(nil)
+	assert.ErrorContains(t, err, "Log.Level: must be one of [debug info warn], got 'verbose'")
+
+	t.Setenv("LOG_LEVEL", "warn")
+	changes, err := reloadConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigChange{{Field: "Log.Level", Old: "info", New: "warn"}}, changes)
+}
+
+func TestReloadConfig_NotSetUp(t *testing.T) {
+	_, err := reloadConfig(nil)
+	assert.EqualError(t, err, "app.ReloadConfig: config was not set up by SetupConfig")
+}
+
+func TestConfigReloadHandler(t *testing.T) {
+	handler := newConfigReloadHandler(func() ([]ConfigChange, error) {
+		return []ConfigChange{{Field: "Log.Level", Old: "info", New: "debug"}}, nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/config/reload", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/config/reload", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct{ Changed []string }
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []string{"Log.Level"}, body.Changed)
+}
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigValidator can be implemented by a config struct, or any struct
+// inside it, to add validations that can't be expressed by tags. It is called
+// by ValidateConfig after the tag validations.
+type ConfigValidator interface {
+	Validate() error
+}
+
+// ConfigViolation is a single failed validation of a config field.
+type ConfigViolation struct {
+	// Field is the path to the field, like HTTP.Port.
+	Field string
+	// Rule is the rule that failed, like required or min=1.
+	Rule string
+	// Message explains the violation.
+	Message string
+}
+
+func (v ConfigViolation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return v.Field + ": " + v.Message
+}
+
+// ConfigValidationError aggregates all the violations found in a config.
+type ConfigValidationError struct {
+	Violations []ConfigViolation
+}
+
+func (e ConfigValidationError) Error() string {
+	s := strings.Builder{}
+	fmt.Fprintf(&s, "invalid config (%d violations)", len(e.Violations))
+	for _, v := range e.Violations {
+		s.WriteString("\n  - ")
+		s.WriteString(v.String())
+	}
+	return s.String()
+}
+
+// ValidateConfig validates config using the `fkvalidate` struct tags and the
+// Validate method of any struct implementing ConfigValidator. All violations
+// are reported together in a ConfigValidationError. The `validate` tags are
+// left to other validators, like go-playground/validator.
+//
+// Rules are separated by commas, like `fkvalidate:"required,min=1"`:
+//   - required: the value must not be the zero value.
+//   - min=N, max=N: numbers must be within the limits. For strings, slices and
+//     maps the limits apply to the length. For time.Duration, N is a duration like 1s.
+//   - oneof=a b c: the value must be one of the space separated values. Empty strings are accepted.
+//   - url: the value must be an absolute URL. Empty strings are accepted.
+//   - duration>0: the time.Duration must be greater than zero.
+func ValidateConfig(config interface{}) error {
+	v := &configValidation{}
+	v.validate(reflect.ValueOf(config), "")
+	if len(v.violations) > 0 {
+		return ConfigValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+type configValidation struct {
+	violations []ConfigViolation
+}
+
+func (v *configValidation) add(field, rule, message string) {
+	v.violations = append(v.violations, ConfigViolation{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+	})
+}
+
+func (v *configValidation) validate(value reflect.Value, path string) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	t := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		fieldValue := value.Field(i)
+
+		if tag, ok := field.Tag.Lookup("fkvalidate"); ok {
+			for _, rule := range strings.Split(tag, ",") {
+				if rule = strings.TrimSpace(rule); rule != "" {
+					v.validateRule(fieldValue, fieldPath, rule)
+				}
+			}
+		}
+
+		v.validate(fieldValue, fieldPath)
+	}
+
+	v.callValidator(value, path)
+}
+
+func (v *configValidation) callValidator(value reflect.Value, path string) {
+	validator, ok := value.Interface().(ConfigValidator)
+	if !ok && value.CanAddr() {
+		validator, ok = value.Addr().Interface().(ConfigValidator)
+	}
+	if !ok {
+		return
+	}
+	if err := validator.Validate(); err != nil {
+		v.add(path, "Validate", err.Error())
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (v *configValidation) validateRule(value reflect.Value, path, rule string) {
+	name, arg, _ := strings.Cut(rule, "=")
+
+	switch name {
+	case "required":
+		if value.IsZero() {
+			v.add(path, rule, "is required")
+		}
+	case "min", "max":
+		v.validateLimit(value, path, rule, name, arg)
+	case "oneof":
+		s := fmt.Sprint(value.Interface())
+		if value.Kind() == reflect.String && s == "" {
+			return
+		}
+		options := strings.Fields(arg)
+		for _, o := range options {
+			if s == o {
+				return
+			}
+		}
+		v.add(path, rule, fmt.Sprintf("must be one of [%s], got '%s'", strings.Join(options, " "), s))
+	case "url":
+		if value.Kind() != reflect.String {
+			v.add(path, rule, "url rule only applies to strings")
+			return
+		}
+		if value.String() == "" {
+			return
+		}
+		u, err := url.Parse(value.String())
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			v.add(path, rule, fmt.Sprintf("must be an absolute URL, got '%s'", value.String()))
+		}
+	case "duration>0":
+		if value.Type() != durationType {
+			v.add(path, rule, "duration>0 rule only applies to time.Duration")
+			return
+		}
+		if value.Int() <= 0 {
+			v.add(path, rule, "must be a duration greater than zero")
+		}
+	default:
+		v.add(path, rule, fmt.Sprintf("unknown validation rule '%s'", rule))
+	}
+}
+
+func (v *configValidation) validateLimit(value reflect.Value, path, rule, name, arg string) {
+	var actual, limit float64
+	var err error
+
+	switch {
+	case value.Type() == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(arg)
+		actual, limit = float64(value.Int()), float64(d)
+	case value.Kind() == reflect.String || value.Kind() == reflect.Slice || value.Kind() == reflect.Map:
+		limit, err = strconv.ParseFloat(arg, 64)
+		actual = float64(value.Len())
+	case value.CanInt():
+		limit, err = strconv.ParseFloat(arg, 64)
+		actual = float64(value.Int())
+	case value.CanUint():
+		limit, err = strconv.ParseFloat(arg, 64)
+		actual = float64(value.Uint())
+	case value.CanFloat():
+		limit, err = strconv.ParseFloat(arg, 64)
+		actual = value.Float()
+	default:
+		v.add(path, rule, fmt.Sprintf("%s rule doesn't apply to %s", name, value.Type()))
+		return
+	}
+	if err != nil {
+		v.add(path, rule, fmt.Sprintf("invalid %s rule argument: %v", name, err))
+		return
+	}
+
+	what := "must be"
+	if value.Kind() == reflect.String || value.Kind() == reflect.Slice || value.Kind() == reflect.Map {
+		what = "length must be"
+	}
+	if name == "min" && actual < limit {
+		v.add(path, rule, fmt.Sprintf("%s at least %s", what, arg))
+	}
+	if name == "max" && actual > limit {
+		v.add(path, rule, fmt.Sprintf("%s at most %s", what, arg))
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValidatedConfig struct {
+	URL       string        `fkvalidate:"required,url"`
+	Level     string        `fkvalidate:"oneof=debug info warn"`
+	Workers   int           `fkvalidate:"min=1,max=10"`
+	Timeout   time.Duration `fkvalidate:"duration>0,max=1m"`
+	Brokers   []string      `fkvalidate:"min=1"`
+	Threshold float64       `fkvalidate:"max=0.5"`
+	Nested    testNestedConfig
+}
+
+type testNestedConfig struct {
+	Name string `fkvalidate:"required"`
+	A, B int
+}
+
+func (c *testNestedConfig) Validate() error {
+	if c.A > c.B {
+		return errors.New("A must not be greater than B")
+	}
+	return nil
+}
+
+func TestValidateConfig(t *testing.T) {
+	valid := testValidatedConfig{
+		URL:       "http://schemaregistry:8081",
+		Level:     "info",
+		Workers:   5,
+		Timeout:   time.Second,
+		Brokers:   []string{"kafka:9092"},
+		Threshold: 0.1,
+		Nested:    testNestedConfig{Name: "nested", A: 1, B: 2},
+	}
+	assert.NoError(t, ValidateConfig(&valid))
+
+	err := ValidateConfig(&testValidatedConfig{
+		URL:       "schemaregistry",
+		Level:     "verbose",
+		Workers:   11,
+		Timeout:   2 * time.Minute,
+		Threshold: 0.7,
+		Nested:    testNestedConfig{A: 2, B: 1},
+	})
+	require.Error(t, err)
+
+	var validationErr ConfigValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, `invalid config (8 violations)
+  - URL: must be an absolute URL, got 'schemaregistry'
+  - Level: must be one of [debug info warn], got 'verbose'
+  - Workers: must be at most 10
+  - Timeout: must be at most 1m
+  - Brokers: length must be at least 1
+  - Threshold: must be at most 0.5
+  - Nested.Name: is required
+  - Nested: A must not be greater than B`, err.Error())
+}
+
+func TestValidateConfig_ZeroValues(t *testing.T) {
+	err := ValidateConfig(&testValidatedConfig{})
+
+	var validationErr ConfigValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	rules := map[string]string{}
+	for _, v := range validationErr.Violations {
+		rules[v.Field] = v.Rule
+	}
+	assert.Equal(t, map[string]string{
+		"URL":         "required",
+		"Workers":     "min=1",
+		"Timeout":     "duration>0",
+		"Brokers":     "min=1",
+		"Nested.Name": "required",
+	}, rules)
+}
+
+func TestValidateConfig_IgnoresValidateTags(t *testing.T) {
+	assert.NoError(t, ValidateConfig(&struct {
+		Email   string `validate:"required,email"`
+		Workers int    `validate:"gte=1"`
+	}{}))
+}
+
+func TestValidateConfig_InvalidRules(t *testing.T) {
+	err := ValidateConfig(&struct {
+		A string `fkvalidate:"unknown"`
+		B bool   `fkvalidate:"min=1"`
+		C int    `fkvalidate:"duration>0"`
+	}{})
+	assert.EqualError(t, err, `invalid config (3 violations)
+  - A: unknown validation rule 'unknown'
+  - B: min rule doesn't apply to bool
+  - C: duration>0 rule only applies to time.Duration`)
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CrashReportConfig configures the crash report written by Recover.
+type CrashReportConfig struct {
+	// Path is the file the crash report is written to. Empty disables the
+	// report. On kubernetes it may be the terminationMessagePath of the
+	// container, like /dev/termination-log, so the cause of the crash is
+	// shown by kubectl describe.
+	Path string
+	// LogLines is how many of the last log lines are added to the report.
+	LogLines int `default:"100"`
+}
+
+// NewDefaultCrashReportConfig returns a disabled CrashReportConfig.
+func NewDefaultCrashReportConfig() CrashReportConfig {
+	return CrashReportConfig{
+		LogLines: 100,
+	}
+}
+
+var crashReport struct {
+	mu     sync.Mutex
+	config CrashReportConfig
+	logs   *logRing
+}
+
+// SetupCrashReport configures the crash report written by Recover when the
+// app panics. It returns a writer that keeps the last log lines for the
+// report, that must be given to log.SetupLogger as an extra log writer:
+//
+//	logs := app.SetupCrashReport(config.CrashReport)
+//	ctx := log.SetupLoggerWithContext(ctx, config.Log, version, logs)
+func SetupCrashReport(config CrashReportConfig) io.Writer {
+	logs := newLogRing(config.LogLines)
+
+	crashReport.mu.Lock()
+	defer crashReport.mu.Unlock()
+	crashReport.config = config
+	crashReport.logs = logs
+	return logs
+}
+
+// writeCrashReport writes the crash report for the recovered value r, if
+// enabled, returning the path of the file.
+func writeCrashReport(r interface{}, stack []byte) (string, error) {
+	crashReport.mu.Lock()
+	config := crashReport.config
+	logs := crashReport.logs
+	crashReport.mu.Unlock()
+
+	if config.Path == "" {
+		return "", nil
+	}
+	return config.Path, os.WriteFile(config.Path, newCrashReport(r, stack, logs), 0o644)
+}
+
+// newCrashReport formats the crash report. Kubernetes only keeps the end of
+// the termination message, so the panic and its stack are the last section.
+func newCrashReport(r interface{}, stack []byte, logs *logRing) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "Crash report at %s\n", time.Now().UTC().Format(time.RFC3339))
+
+	info := ReadBuildInfo()
+	fmt.Fprintln(&b, "\n=== Build info ===")
+	fmt.Fprintf(&b, "path: %s\nversion: %s\ngo_version: %s\nrevision: %s\ntime: %s\ndirty: %t\n",
+		info.Path, info.Version, info.GoVersion, info.Revision, info.Time, info.Dirty)
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintln(&b, "\n=== Memory ===")
+	fmt.Fprintf(&b, "heap_alloc: %d\nheap_inuse: %d\nheap_objects: %d\nsys: %d\nnum_gc: %d\ngoroutines: %d\n",
+		mem.HeapAlloc, mem.HeapInuse, mem.HeapObjects, mem.Sys, mem.NumGC, runtime.NumGoroutine())
+
+	if logs != nil {
+		fmt.Fprintln(&b, "\n=== Last log lines ===")
+		for _, line := range logs.lines() {
+			b.Write(line)
+			if !bytes.HasSuffix(line, []byte("\n")) {
+				b.WriteByte('\n')
+			}
+		}
+	}
+
+	fmt.Fprintln(&b, "\n=== Goroutines ===")
+	b.Write(allStacks())
+
+	fmt.Fprintln(&b, "\n\n=== Panic ===")
+	fmt.Fprintf(&b, "%v\n\n", r)
+	b.Write(stack)
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes()
+}
+
+// logRing is an io.Writer that keeps the last writes, each one being a log line.
+type logRing struct {
+	mu   sync.Mutex
+	buf  [][]byte
+	next int
+	full bool
+}
+
+func newLogRing(size int) *logRing {
+	return &logRing{buf: make([][]byte, max(size, 0))}
+}
+
+func (r *logRing) Write(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		return len(p), nil
+	}
+
+	// The logger reuses p, so it must be copied
+	line := append([]byte(nil), p...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = line
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+// lines returns the kept lines from the oldest to the newest.
+func (r *logRing) lines() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([][]byte(nil), r.buf[:r.next]...)
+	}
+	return append(append([][]byte(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
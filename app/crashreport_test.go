@@ -0,0 +1,64 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRing(t *testing.T) {
+	r := newLogRing(2)
+	assert.Empty(t, r.lines())
+
+	buf := []byte("first")
+	_, _ = r.Write(buf)
+	copy(buf, "xxxxx")
+	assert.Equal(t, [][]byte{[]byte("first")}, r.lines(), "written lines must be copied")
+
+	_, _ = r.Write([]byte("second"))
+	_, _ = r.Write([]byte("third"))
+	assert.Equal(t, [][]byte{[]byte("second"), []byte("third")}, r.lines())
+
+	n, err := newLogRing(0).Write([]byte("discarded"))
+	assert.NoError(t, err)
+	assert.Equal(t, 9, n)
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	defer SetupCrashReport(NewDefaultCrashReportConfig())
+
+	path, err := writeCrashReport("my panic", []byte("my stack"))
+	assert.NoError(t, err)
+	assert.Empty(t, path, "crash report must be disabled by default")
+
+	config := NewDefaultCrashReportConfig()
+	config.Path = filepath.Join(t.TempDir(), "termination-log")
+	config.LogLines = 2
+	logger := zerolog.New(SetupCrashReport(config))
+	logger.Info().Msg("first")
+	logger.Info().Msg("second")
+	logger.Error().Msg("third")
+
+	path, err = writeCrashReport("my panic", []byte("my stack"))
+	require.NoError(t, err)
+	assert.Equal(t, config.Path, path)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	report := string(b)
+
+	assert.Contains(t, report, "=== Build info ===\n")
+	assert.Contains(t, report, "heap_inuse: ")
+	assert.Contains(t, report, "=== Last log lines ===\n"+
+		`{"level":"info","message":"second"}`+"\n"+
+		`{"level":"error","message":"third"}`+"\n")
+	assert.NotContains(t, report, `"first"`)
+	assert.Contains(t, report, "app.TestWriteCrashReport(")
+	assert.True(t, strings.HasSuffix(report, "=== Panic ===\nmy panic\n\nmy stack\n"),
+		"panic must be at the end of the report:\n%s", report)
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"net/http"
 	"time"
 )
 
@@ -21,7 +22,12 @@ var (
 // NewDefaultApp creates and sets the default app. The default app is controlled by
 // public functions in app package
 func NewDefaultApp(ctx context.Context) (err error) {
-	defaultApp, err = New(ctx, DefaultAdminPort)
+	return NewDefaultAppWithConfig(ctx, NewDefaultConfig())
+}
+
+// NewDefaultAppWithConfig creates and sets the default app using config.
+func NewDefaultAppWithConfig(ctx context.Context, config Config) (err error) {
+	defaultApp, err = NewWithConfig(ctx, config)
 	if err != nil {
 		return err
 	}
@@ -38,6 +44,54 @@ func RunAndWait(f MainLoopFunc) {
 	defaultApp.RunAndWait(f)
 }
 
+// RunAndWaitWithReady calls the RunAndWaitWithReady of the default app
+func RunAndWaitWithReady(f MainLoopWithReadyFunc) {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	defaultApp.RunAndWaitWithReady(f)
+}
+
+// Go calls the Go of the default app
+func Go(name string, loop MainLoopFunc, policy RestartPolicy) {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	defaultApp.Go(name, loop, policy)
+}
+
+// StartProfiler calls the StartProfiler of the default app
+func StartProfiler(config ProfilerConfig) error {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	return defaultApp.StartProfiler(config)
+}
+
+// OnDrain calls the OnDrain of the default app
+func OnDrain(name string, drain func(context.Context) error, undrain func(context.Context) error) {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	defaultApp.OnDrain(name, drain, undrain)
+}
+
+// Drain calls the Drain of the default app
+func Drain(ctx context.Context) error {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	return defaultApp.Drain(ctx)
+}
+
+// Undrain calls the Undrain of the default app
+func Undrain(ctx context.Context) error {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	return defaultApp.Undrain(ctx)
+}
+
 // Shutdown calls the Shutdown of the default app
 func Shutdown(ctx context.Context) error {
 	if defaultApp == nil {
@@ -54,6 +108,14 @@ func RegisterShutdownHandler(sh *ShutdownHandler) {
 	defaultApp.RegisterShutdownHandler(sh)
 }
 
+// AdminHandle calls the AdminHandle of the default app
+func AdminHandle(pattern string, handler http.Handler) {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	defaultApp.AdminHandle(pattern, handler)
+}
+
 // ReadinessProbeGoup TODO
 func ReadinessProbeGoup() *ProbeGroup {
 	if defaultApp == nil {
@@ -69,3 +131,11 @@ func HealthinessProbeGroup() *ProbeGroup {
 	}
 	return &defaultApp.Healthy
 }
+
+// StartupProbeGroup returns the startup probe group of the default app
+func StartupProbeGroup() *ProbeGroup {
+	if defaultApp == nil {
+		panic("default app not initialized")
+	}
+	return &defaultApp.Startup
+}
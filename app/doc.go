@@ -15,6 +15,32 @@ The first thing you should do is setup a configuration and logger. The app uses
 	ctx := log.SetupLoggerWithContext(context.Background(), config.Log, version)
 	app.NewDefaultApp(ctx)
 
+SetupConfig fills the configuration struct from layered sources, each one overriding the previous: the `default` struct tags, the base config file (config.json, or config.yaml, config.yml or config.toml), the environment profile file named after the APP_ENV environment variable (like config.staging.yaml), the files passed with -config (repeatable, in the given order), environment variables and, at last, command line flags. The file format is chosen by its extension. JSON and TOML keys match the field names case insensitively, while YAML keys are the lowercased field names unless a `yaml` tag is given.
+
+	APP_ENV=staging ./myapp -config /etc/myapp/secrets.yaml -log-level=debug
+
+After loading, SetupConfig validates the configuration using the `fkvalidate` struct tags (required, min, max, oneof, url and duration>0) and the Validate method of any struct implementing ConfigValidator. All violations are reported at once and the application exits.
+
+	var config struct {
+		Workers  int           `default:"4" fkvalidate:"min=1,max=64"`
+		Registry string        `fkvalidate:"required,url"`
+		Timeout  time.Duration `default:"5s" fkvalidate:"duration>0"`
+	}
+
+The configuration can be reloaded without restarting the application by sending a SIGHUP or a POST request to the /debug/config/reload admin endpoint. The sources are read and validated again, and the callbacks registered with OnConfigChange are called for every changed field marked with the `reload:"true"` tag. Marking a struct marks all its fields. Changes to other fields are ignored with a warning because they need a restart. The struct given to SetupConfig is never modified, the new values are only delivered to the callbacks.
+
+	var config struct {
+		Log log.Config `reload:"true"`
+	}
+	app.SetupConfig(&config)
+	app.OnConfigChange("Log.Level", func(c app.ConfigChange) {
+		zerolog.SetGlobalLevel(log.MustParseLevel(c.New.(string)))
+	})
+
+The effective configuration, after all sources are merged and reloads applied, is served as JSON by the /debug/config admin endpoint and returned by EffectiveConfig. It is flattened like log.Flatten, with the values of fields tagged `secret:"true"` replaced by log.Redacted, and shows the source of each value (default, file, env or flag) and the file it came from:
+
+	{"Log.Level":{"value":"debug","source":"file","file":"config.staging.yaml"},"Password":{"value":"[REDACTED]","source":"env"}}
+
 At this point, the app will already be exposing the admin port and the readiness probe will be returning error, indicating that the application is not yet ready to receive requests.
 
 Then you should start initializing all the program dependencies. Because the application is not yet ready, kubernetes will refrain from sending requests (that would fail at this point). Also we already have some metrics and the debug handlers.
@@ -30,7 +56,18 @@ During this phase, you will probably want to register your shutdown handlers.
 		},
 	)
 
-They are executed in order by priority. The Highest priority first (in case of the same priority, don't assume any order).
+They are executed in order by priority. The Highest priority first. Handlers with the same priority are executed in parallel, so don't assume any order among them.
+
+Instead of encoding an order with magic priority numbers, a handler can declare which handlers of the same priority must finish before it starts:
+
+	app.RegisterShutdownHandler(&app.ShutdownHandler{Name: "http_server", Handler: httpServer.Shutdown})
+	app.RegisterShutdownHandler(&app.ShutdownHandler{Name: "producer", Handler: producer.Flush, DependsOn: []string{"http_server"}})
+	app.RegisterShutdownHandler(&app.ShutdownHandler{Name: "database", Handler: db.Close, DependsOn: []string{"producer"}})
+	app.RegisterShutdownHandler(&app.ShutdownHandler{Name: "cache", Handler: cache.Close})
+
+In this example "cache" runs in parallel with the "http_server" -> "producer" -> "database" chain. Unknown dependencies and dependency cycles are logged and ignored so no handler is left behind. If a handler with ErrorPolicyAbort fails, no other handler is started.
+
+At the end of the shutdown a report is logged at info level with the duration, outcome and policy of every handler. The outcome tells if the handler succeeded, failed, was skipped because the deadline was reached, was still running when the deadline was reached or was never started. The same information is exported as the fkit_app_shutdown_duration_seconds, fkit_app_shutdown_handler_duration_seconds and fkit_app_shutdown_handler_outcomes_total metrics, and the report is available through LastShutdownReport.
 
 Finally you can run the application by calling RunAndWait:
 
@@ -40,6 +77,119 @@ Finally you can run the application by calling RunAndWait:
 
 At this point the application will run until the given function returns or it receives an termination signal.
 
+RunAndWait sets the app as ready right before calling the main loop. If the main loop needs to warm up before receiving traffic, like filling caches or subscribing to consumers, use RunAndWaitWithReady and call ready when done:
+
+	app.RunAndWaitWithReady(func(ready func()) error {
+		if err := cache.Warmup(ctx); err != nil {
+			return err
+		}
+		ready()
+		return consumer.Run(ctx)
+	})
+
+# Supervised Loops
+
+Applications that run more than one loop, like an HTTP server, a consumer and a scheduler, can register each one with Go instead of writing their own plumbing inside the main loop:
+
+	app.Go("consumer", consumer.Run, app.RestartPolicy{
+		Mode:        app.RestartOnFailure,
+		MaxRestarts: 5,
+		Backoff:     time.Second,
+		MaxBackoff:  time.Minute,
+		Critical:    true,
+	})
+	app.Go("scheduler", scheduler.Run, app.RestartPolicy{Mode: app.RestartAlways})
+
+	app.RunAndWait(func() error {
+		return httpServer.ListenAndServe()
+	})
+
+Each loop gets a readiness probe named "fkit/loop/<name>" and panics are recovered as errors. A loop is restarted with an exponential backoff according to its policy, reset when the loop returns without error or runs for longer than the max backoff. When a critical loop finishes and is not going to be restarted, the app starts a graceful shutdown just like when the main loop finishes. Once the shutdown starts loops are not restarted anymore, so they should still be stopped by shutdown handlers. If all the work is done by supervised loops, RunAndWait can be called with a nil main loop.
+
+The metrics fkit_app_loop_running, fkit_app_loop_exits_total and fkit_app_loop_restarts_total are exported for every loop.
+
+# Admin Server
+
+The admin server is started by New and binds synchronously, so an address already in use or an invalid TLS certificate makes New return an error instead of failing silently. Its address, TLS files and timeouts can be configured with the AdminConfig in the app Config, which can be embedded in your configuration struct:
+
+	var config struct {
+		Log log.Config
+		App app.Config
+	}
+
+	app.SetupConfig(&config)
+	ctx := log.SetupLoggerWithContext(context.Background(), config.Log, version)
+	app.NewDefaultAppWithConfig(ctx, config.App)
+
+Extra operational endpoints can be served by the admin server instead of spinning up another HTTP server:
+
+	app.AdminHandle("/debug/cache", cacheDebugHandler)
+
+The build metadata embedded by the go toolchain, like the VCS revision and time, the dirty flag, the go version and the versions of all modules, is served as JSON at /version and returned by ReadBuildInfo. The fkit_app_build_info metric is always 1 and carries the same metadata as labels, except the modules, so incidents can be correlated with deploys.
+
+The global log level can be read and changed at runtime through /debug/loglevel, or by SetLogLevel. A GET returns the current level and a PUT changes it. With a ttl the level is reverted automatically when it expires, and every change is logged:
+
+	curl -X PUT 'localhost:9000/debug/loglevel?level=debug&ttl=5m'
+
+The /debug/ routes, like pprof, the dumps, the effective config, the config reload and the log level, can be protected by AdminConfig.Auth with a bearer token, basic auth or client certificates verified against a CA file (which requires TLS). Any configured method grants access. Probes, /metrics and the other routes stay open so kubelet and Prometheus keep working. Handlers added with AdminHandle under /debug/ are protected as well.
+
+	ADMIN_AUTH_BEARERTOKEN=s3cr3t ./myapp
+	curl -H 'Authorization: Bearer s3cr3t' localhost:9000/debug/pprof/heap
+
+A misbehaving instance can be pulled out of rotation for debugging without killing it. A POST to /debug/drain, or calling Drain, sets the readiness probe as not ok and calls the hooks registered with OnDrain, which should stop consumers from pulling work. A POST to /debug/undrain, or calling Undrain, resumes them and restores the readiness:
+
+	app.OnDrain("consumer", consumer.Pause, consumer.Resume)
+
+The error codes registered with errors.RegisterCode, with their severity, HTTP status, retryability, description and owner, are served as JSON by /debug/errors, so API consumers can discover them.
+
+The admin server is shut down automatically as the last step of the graceful shutdown, so probes and metrics remain available while the other shutdown handlers run.
+
+# Runtime Tuning
+
+When running in a container, the app can set GOMEMLIMIT from the cgroup memory limit multiplied by a ratio that leaves headroom for memory outside the heap. GOMAXPROCS is left to the go runtime, which already follows the cgroup CPU quota. It can also export all the go runtime/metrics on /metrics. Both are opt-in and configured by the Runtime field of Config, applied by NewWithConfig before anything else:
+
+	var config struct {
+		Log log.Config
+		App app.Config
+	}
+	app.SetupConfig(&config)
+	ctx := log.SetupLoggerWithContext(context.Background(), config.Log, version)
+	app.NewDefaultAppWithConfig(ctx, config.App)
+
+	APP_RUNTIME_TUNE=true APP_RUNTIME_MEMORYLIMITRATIO=0.8 APP_RUNTIME_METRICS=true ./myapp
+
+The GOMEMLIMIT environment variable takes precedence, and every decision is logged.
+
+# Crash Report
+
+Recover logs the panic with a truncated stack. SetupCrashReport makes it also write a crash report with the full stack, all the goroutines, memory stats, the build info and the last log lines. The returned writer keeps the log lines and must be given to the logger:
+
+	defer app.Recover()
+	app.SetupConfig(&config)
+	logs := app.SetupCrashReport(config.CrashReport)
+	ctx := log.SetupLoggerWithContext(context.Background(), config.Log, version, logs)
+
+With CRASHREPORT_PATH=/dev/termination-log, the kubernetes default terminationMessagePath, the cause of a crash loop is shown by kubectl describe. Kubernetes only keeps the end of the file, so the panic is written last.
+
+# Leak Check
+
+Consumers and tickers that survive the shutdown keep working while the new version is already running. With APP_LEAKCHECK_ENABLED=true the goroutines alive after the shutdown are compared to the ones alive when the App was created. The ones that don't finish within APP_LEAKCHECK_TIMEOUT are logged with their stacks, added to the ShutdownReport and counted by the fkit_app_shutdown_leaked_goroutines metric. Goroutines of libraries that are expected to live until the process exits can be ignored by function name in LeakCheckConfig.Ignore. The apptest package enables the check and fails the test if any goroutine leaks.
+
+# Continuous Profiling
+
+Besides the on-demand pprof endpoints, an opt-in background profiler can write CPU, heap, goroutine and mutex profiles to a directory. A snapshot is taken every interval, when the heap in use crosses a threshold and when the process receives a SIGUSR1. The oldest files are removed to respect the count and size limits:
+
+	var config struct {
+		Profiler app.ProfilerConfig
+	}
+	app.SetupConfig(&config)
+	...
+	if err := app.StartProfiler(config.Profiler); err != nil {
+		log.Fatal().Err(err).Msg("Failed to start profiler")
+	}
+
+The profiler is enabled with PROFILER_ENABLED=true and stopped by a shutdown handler. The fkit_app_profiler_snapshots_total metric counts the snapshots by trigger.
+
 # Updating From Previous Version
 
 On the previous version,the NewDefaultApp received the main loop:
@@ -54,35 +204,22 @@ To break this cycle the main loop was moved from the NewDefaultApp and was place
 
 # Using Probes
 
-A Probe is a boolean that indicates if something is OK or not. There are two groups of probes in an app: The Healthiness an Readiness groups. Kubernetes checks on there two probes to decide what to do to the pod, like, from stop sending requests to just kill the pod, sending a signal the app will capture and start a graceful shutdown.
+A Probe is a boolean that indicates if something is OK or not. There are three groups of probes in an app: The Healthiness, Readiness and Startup groups, served at /healthy, /ready and /startup. Kubernetes checks on these three probes to decide what to do to the pod, like, from stop sending requests to just kill the pod, sending a signal the app will capture and start a graceful shutdown.
 
-If a single probe of a group is not ok, than the whole group is not ok. In this event, the HTTP handler returns the name of all the probes that are not ok for the given group.
+If a single probe of a group is not ok, than the whole group is not ok. In this event, the HTTP handler returns the name of all the probes that are not ok for the given group, sorted by name. The /healthy endpoint replies with 500 and the /ready endpoint with 503.
 
-	mux.HandleFunc("/healthy", func(w http.ResponseWriter, _ *http.Request) {
-		isHealthy, cause := app.Healthy.CheckProbes()
-		if isHealthy {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("OK"))
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(cause))
-		}
-	})
-	mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
-		isReady, cause := app.Ready.CheckProbes()
-		if isReady {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("OK"))
-		} else {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			w.Write([]byte(cause))
-		}
-	})
+	isReady, cause := app.ReadinessProbeGoup().CheckProbes()
+
+When the request accepts "application/json" or has the "verbose" query parameter (like /ready?verbose), the reply is a JSON document listing every probe with its state, the time of its last transition and the reason it is not ok:
+
+	{"ok":false,"probes":[{"name":"database","ok":false,"last_transition":"2024-01-02T15:04:05Z","reason":"connection refused"},{"name":"fkit/app","ok":true,"last_transition":"2024-01-02T15:04:00Z"}]}
 
 If the application is unhealthy kubernetes will send a signal that will trigger the graceful shutdown. All registered shutdown handlers will be executed ordered by priority (highest first) and the pod will be restarted. Only set an application as unhealthy if it reached an unworkable state and should be restarted. We have an example of this on `gokitmiddlewares/stalemiddleware/`. This is a middleware that was developed to be used in workers. It checks if the endpoint is being called (messages are being fetched and processed) and if not, it assumes there could be a problem with the queue and sets the application to unready, causing the application to restart. This mitigated a problem we had with kafka when a change of brokers made the worker stop receiving messages forever.
 
 If the application is unready kubernetes will stop sending requests, but if the application becomes ready again, it will start receiving requests. This is used during initialization to signalize to kubernetes when the application is ready and can receive requests. If we can identify that the the application is degraded we can use this probe to temporary remove the application from the kubernetes service until it recovers.
 
+The startup group is meant for kubernetes' startup probe. It's not ok until the main loop signals the app is ready for the first time and it never goes back to not ok. This allows slow starting applications without relaxing the liveness probe.
+
 A probe only exists as part of a group so the group provides a proper constructor for a probe. Probe's name must also be unique for the group but can be reused on different groups.
 
 	readinessProbe, err := app.Ready.NewProbe("fkit/app", false)
@@ -94,5 +231,26 @@ The state of a probe can be altered at any time using SetOk and SetNotOk:
 
 	readinessProbe.SetOk()
 	readinessProbe.SetNotOk()
+
+SetError sets the probe as not ok and records the error as the reason:
+
+	readinessProbe.SetError(err)
+
+Instead of flipping a probe by hand, a probe can be actively checked. AddCheck runs the given function on the background every interval, canceling it after the timeout, and caches the result of the last run. The error of a failing check is reported together with the probe name.
+
+	err := app.ReadinessProbeGoup().AddCheck("database", db.PingContext, 10*time.Second, time.Second)
+
+Checks are stopped automatically at the end of the shutdown.
+
+# Testing
+
+The apptest package runs an App in a test without OS signals or real sleeps, failing it if the shutdown leaks goroutines. The grace period is waited on the App.Clock and signals are read from App.Signals, both replaced by the harness:
+
+	h := apptest.New(t)
+	h.App.GracePeriod = time.Minute
+	h.Run(mainLoop)
+	h.WaitReady()
+	h.Stop()
+	h.AssertOutcome("database", app.ShutdownOutcomeSuccess)
 */
 package app
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// errDraining is the reason of the main readiness probe while draining.
+var errDraining = errors.New("draining")
+
+type drainHook struct {
+	name    string
+	drain   func(context.Context) error
+	undrain func(context.Context) error
+}
+
+// OnDrain registers hooks called when the app is drained and undrained, like
+// stopping and resuming consumers so they don't pull work while drained.
+// Hooks are called in the order they were registered. Undrain may be nil.
+func (a *App) OnDrain(name string, drain func(context.Context) error, undrain func(context.Context) error) {
+	if drain == nil {
+		panic("drain hook must not be nil")
+	}
+	a.drainMu.Lock()
+	defer a.drainMu.Unlock()
+	a.drainHooks = append(a.drainHooks, drainHook{name: name, drain: drain, undrain: undrain})
+
+	log.Trace().Str("drain_hook", name).Msg("[app] Drain hook registered.")
+}
+
+// Drain puts the app in maintenance mode. The readiness probe is set as not
+// ok, taking the app out of rotation without killing it, and the OnDrain
+// hooks are called. The app stays drained even if a hook fails, and draining
+// an already drained app does nothing.
+func (a *App) Drain(ctx context.Context) error {
+	const op = errors.Op("app.App.Drain")
+
+	a.drainMu.Lock()
+	if a.draining {
+		a.drainMu.Unlock()
+		return nil
+	}
+	a.draining = true
+	a.mainReadinessProbe.SetError(errDraining)
+	hooks := append([]drainHook(nil), a.drainHooks...)
+	a.drainMu.Unlock()
+
+	a.logger.Warn().Msg("Application drained, readiness is not ok until it's undrained.")
+
+	var errs []error
+	for _, h := range hooks {
+		if err := h.drain(ctx); err != nil {
+			errs = append(errs, errors.E(err, errors.KV("drain_hook", h.name)))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.E(op, errors.ConcatErrors(errs...))
+	}
+	return nil
+}
+
+// Undrain takes the app out of maintenance mode. The undrain hooks are called
+// and then the readiness probe is restored, unless the app was not ready yet
+// or the shutdown has started. Undraining an app that is not drained does nothing.
+func (a *App) Undrain(ctx context.Context) error {
+	const op = errors.Op("app.App.Undrain")
+
+	a.drainMu.Lock()
+	if !a.draining {
+		a.drainMu.Unlock()
+		return nil
+	}
+	hooks := append([]drainHook(nil), a.drainHooks...)
+	a.drainMu.Unlock()
+
+	var errs []error
+	for _, h := range hooks {
+		if h.undrain == nil {
+			continue
+		}
+		if err := h.undrain(ctx); err != nil {
+			errs = append(errs, errors.E(err, errors.KV("drain_hook", h.name)))
+		}
+	}
+
+	a.drainMu.Lock()
+	a.draining = false
+	if a.ready {
+		a.mainReadinessProbe.SetOk()
+	} else {
+		a.mainReadinessProbe.SetNotOk()
+	}
+	a.drainMu.Unlock()
+
+	a.logger.Warn().Msg("Application undrained.")
+
+	if len(errs) > 0 {
+		return errors.E(op, errors.ConcatErrors(errs...))
+	}
+	return nil
+}
+
+// IsDraining tells if the app is drained.
+func (a *App) IsDraining() bool {
+	a.drainMu.Lock()
+	defer a.drainMu.Unlock()
+	return a.draining
+}
+
+// setReady sets the main readiness probe, unless the app is drained. The
+// state is restored when the app is undrained.
+func (a *App) setReady(ready bool) {
+	a.drainMu.Lock()
+	defer a.drainMu.Unlock()
+	a.ready = ready
+	if a.draining {
+		return
+	}
+	a.mainReadinessProbe.Set(ready)
+}
+
+// newDrainHandler returns a handler that calls action on POST requests and
+// replies with the drain state on any request.
+func newDrainHandler(a *App, action func(*App, context.Context) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if err := action(a, r.Context()); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Draining bool `json:"draining"`
+		}{
+			Draining: a.IsDraining(),
+		})
+	})
+}
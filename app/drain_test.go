@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	a := newTestApp(t)
+
+	var calls []string
+	a.OnDrain("consumer",
+		func(context.Context) error { calls = append(calls, "drain consumer"); return nil },
+		func(context.Context) error { calls = append(calls, "undrain consumer"); return nil },
+	)
+	a.OnDrain("scheduler",
+		func(context.Context) error { calls = append(calls, "drain scheduler"); return errors.New("my error") },
+		nil,
+	)
+	a.setReady(true)
+
+	err := a.Drain(context.Background())
+	assert.EqualError(t, err, "app.App.Drain: my error [drain_hook=scheduler]")
+	assert.True(t, a.IsDraining())
+	ok, cause := a.Ready.CheckProbes()
+	assert.False(t, ok)
+	assert.Equal(t, "fkit/app (draining)", cause)
+
+	require.NoError(t, a.Drain(context.Background()), "draining twice must do nothing")
+
+	// Becoming ready while drained must not put the app back in rotation
+	a.setReady(true)
+	ok, _ = a.Ready.CheckProbes()
+	assert.False(t, ok)
+
+	require.NoError(t, a.Undrain(context.Background()))
+	assert.False(t, a.IsDraining())
+	ok, _ = a.Ready.CheckProbes()
+	assert.True(t, ok)
+
+	assert.Equal(t, []string{"drain consumer", "drain scheduler", "undrain consumer"}, calls)
+}
+
+func TestUndrain_NotReady(t *testing.T) {
+	a := newTestApp(t)
+
+	require.NoError(t, a.Drain(context.Background()))
+	require.NoError(t, a.Undrain(context.Background()))
+
+	ok, cause := a.Ready.CheckProbes()
+	assert.False(t, ok, "undrain must not set an app that was never ready as ready")
+	assert.Equal(t, "fkit/app", cause)
+}
+
+func TestDrainHandler(t *testing.T) {
+	a := newTestApp(t)
+
+	post := func(path string) string {
+		resp, err := http.Post("http://"+a.AdminAddr().String()+path, "", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return string(body)
+	}
+
+	assert.JSONEq(t, `{"draining":true}`, post("/debug/drain"))
+	_, body := adminGet(t, a, "/debug/drain")
+	assert.JSONEq(t, `{"draining":true}`, body)
+	assert.JSONEq(t, `{"draining":false}`, post("/debug/undrain"))
+}
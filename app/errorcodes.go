@@ -0,0 +1,21 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+// newErrorCodesHandler returns a handler that replies with the error codes
+// registered in the errors catalog, so API consumers know what to expect.
+func newErrorCodesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Codes []errors.CodeInfo `json:"codes"`
+		}{
+			Codes: errors.Codes(),
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodesEndpoint(t *testing.T) {
+	code := errors.RegisterCode(errors.CodeInfo{
+		Code:        "APP_TEST_CODE",
+		Severity:    errors.SeverityInput,
+		HTTPStatus:  http.StatusConflict,
+		Description: "Test code.",
+		Owner:       "app",
+	})
+
+	a := newTestApp(t)
+
+	status, body := adminGet(t, a, "/debug/errors")
+	assert.Equal(t, http.StatusOK, status)
+
+	var response struct {
+		Codes []errors.CodeInfo `json:"codes"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &response))
+	info, _ := errors.LookupCode(code)
+	assert.Contains(t, response.Codes, info)
+	assert.Equal(t, errors.Codes(), response.Codes)
+}
@@ -16,14 +16,17 @@ import (
 var version = "development"
 
 var config struct {
-	Log log.Config
+	Log         log.Config
+	CrashReport app.CrashReportConfig
 }
 
 func main() {
 	defer app.Recover()
 
 	app.SetupConfig(&config)
-	ctx := log.SetupLoggerWithContext(context.Background(), config.Log, version)
+	// Use -crashreport-path=/dev/termination-log to write a crash report
+	logs := app.SetupCrashReport(config.CrashReport)
+	ctx := log.SetupLoggerWithContext(context.Background(), config.Log, version, logs)
 
 	// New app
 	app.NewDefaultApp(ctx)
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+)
+
+// LeakCheckConfig configures the check for goroutines leaked by the shutdown.
+type LeakCheckConfig struct {
+	// Enabled compares the live goroutines after the shutdown with the ones
+	// alive when the App was created, reporting the new ones as leaked.
+	Enabled bool `default:"false"`
+	// Timeout is how long the goroutines have to finish after the shutdown
+	// before being reported as leaked.
+	Timeout time.Duration `default:"1s"`
+	// Ignore are functions, like "github.com/some/lib.(*Client).loop", whose
+	// goroutines are never reported, even if they are anywhere in the stack.
+	Ignore []string
+}
+
+// NewDefaultLeakCheckConfig returns a disabled LeakCheckConfig.
+func NewDefaultLeakCheckConfig() LeakCheckConfig {
+	return LeakCheckConfig{
+		Timeout: time.Second,
+	}
+}
+
+// leakCheckIgnored are goroutines started once for the whole process, that
+// are expected to outlive the App.
+var leakCheckIgnored = []string{
+	"os/signal.signal_recv",
+}
+
+// LeakedGoroutine is a goroutine still alive after the shutdown that was not
+// alive when the App was created.
+type LeakedGoroutine struct {
+	ID int
+	// State is the state of the goroutine, like "chan receive" or "select".
+	State string
+	// Function is the function the goroutine is currently in.
+	Function string
+	// CreatedBy is the function that started the goroutine.
+	CreatedBy string
+	// Stack is the stack trace of the goroutine.
+	Stack string
+}
+
+// MarshalZerologObject allows for zerolog to log the goroutine as an object.
+func (g LeakedGoroutine) MarshalZerologObject(e *zerolog.Event) {
+	e.Int("id", g.ID).
+		Str("state", g.State).
+		Str("function", g.Function).
+		Str("created_by", g.CreatedBy).
+		Str("stack", g.Stack)
+}
+
+var leakedGoroutines = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "fkit",
+	Subsystem: "app",
+	Name:      "shutdown_leaked_goroutines",
+	Help:      "Amount of goroutines leaked by the last graceful shutdown.",
+})
+
+// goroutineBaseline are the ids of the goroutines alive at some point.
+type goroutineBaseline map[int]bool
+
+func newGoroutineBaseline() goroutineBaseline {
+	baseline := goroutineBaseline{}
+	for _, g := range liveGoroutines() {
+		baseline[g.ID] = true
+	}
+	return baseline
+}
+
+// checkLeaks waits up to config.Timeout for the goroutines not in the
+// baseline to finish, returning the ones that didn't. The calling goroutine
+// is never reported.
+func (b goroutineBaseline) checkLeaks(config LeakCheckConfig) []LeakedGoroutine {
+	const interval = 10 * time.Millisecond
+
+	deadline := time.Now().Add(config.Timeout)
+	for {
+		leaked := b.leaked(config.Ignore)
+		if len(leaked) == 0 || !time.Now().Before(deadline) {
+			return leaked
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (b goroutineBaseline) leaked(ignore []string) []LeakedGoroutine {
+	goroutines := liveGoroutines()
+	// The first goroutine is the current one
+	if len(goroutines) > 0 {
+		goroutines = goroutines[1:]
+	}
+
+	var leaked []LeakedGoroutine
+	for _, g := range goroutines {
+		if b[g.ID] || stackContainsAny(g.Stack, leakCheckIgnored) || stackContainsAny(g.Stack, ignore) {
+			continue
+		}
+		leaked = append(leaked, g)
+	}
+	return leaked
+}
+
+func stackContainsAny(stack string, functions []string) bool {
+	for _, f := range functions {
+		if strings.Contains(stack, f+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// liveGoroutines returns all the goroutines, starting by the current one.
+func liveGoroutines() []LeakedGoroutine {
+	var goroutines []LeakedGoroutine
+	for _, block := range bytes.Split(allStacks(), []byte("\n\n")) {
+		if g, ok := parseGoroutine(string(block)); ok {
+			goroutines = append(goroutines, g)
+		}
+	}
+	return goroutines
+}
+
+// allStacks returns the stack of all the goroutines, starting by the current one.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// parseGoroutine parses a goroutine of a runtime.Stack dump, that looks like:
+//
+//	goroutine 7 [chan receive, 2 minutes]:
+//	main.consume(...)
+//		/src/main.go:42 +0x1d
+//	created by main.main in goroutine 1
+//		/src/main.go:12 +0x2a
+func parseGoroutine(block string) (LeakedGoroutine, bool) {
+	header, stack, _ := strings.Cut(block, "\n")
+	rest, ok := strings.CutPrefix(header, "goroutine ")
+	if !ok {
+		return LeakedGoroutine{}, false
+	}
+	id, rest, ok := strings.Cut(rest, " [")
+	if !ok {
+		return LeakedGoroutine{}, false
+	}
+	g := LeakedGoroutine{Stack: stack}
+	var err error
+	if g.ID, err = strconv.Atoi(id); err != nil {
+		return LeakedGoroutine{}, false
+	}
+	g.State, _, _ = strings.Cut(strings.TrimSuffix(rest, "]:"), ",")
+
+	lines := strings.Split(stack, "\n")
+	if len(lines) > 0 {
+		g.Function = stackFunction(lines[0])
+	}
+	for _, line := range lines {
+		if createdBy, ok := strings.CutPrefix(line, "created by "); ok {
+			g.CreatedBy, _, _ = strings.Cut(createdBy, " in goroutine ")
+		}
+	}
+	return g, true
+}
+
+// stackFunction removes the arguments from a stack trace function line.
+func stackFunction(line string) string {
+	if i := strings.LastIndex(line, "("); i > 0 {
+		return line[:i]
+	}
+	return line
+}
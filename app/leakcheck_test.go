@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown_LeakCheck(t *testing.T) {
+	a := &App{
+		leakCheck:         LeakCheckConfig{Enabled: true, Timeout: 50 * time.Millisecond},
+		goroutineBaseline: newGoroutineBaseline(),
+	}
+
+	leak := make(chan struct{})
+	defer close(leak)
+	go leakingConsumer(leak)
+
+	// Goroutines finishing within the timeout are not leaked
+	finishing := make(chan struct{})
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:    "consumer",
+		Handler: func(context.Context) error { close(finishing); return nil },
+	})
+	go func() {
+		<-finishing
+		time.Sleep(10 * time.Millisecond)
+	}()
+
+	require.NoError(t, a.Shutdown(context.Background()))
+
+	report := a.LastShutdownReport()
+	require.NotNil(t, report)
+	require.Len(t, report.LeakedGoroutines, 1)
+	g := report.LeakedGoroutines[0]
+	assert.Equal(t, "chan receive", g.State)
+	assert.Equal(t, "github.com/arquivei/foundationkit/app.leakingConsumer", g.Function)
+	assert.Equal(t, "github.com/arquivei/foundationkit/app.TestShutdown_LeakCheck", g.CreatedBy)
+	assert.Contains(t, g.Stack, "leakcheck_test.go")
+	assert.Equal(t, 1.0, testutil.ToFloat64(leakedGoroutines))
+}
+
+func TestShutdown_LeakCheckIgnore(t *testing.T) {
+	a := &App{
+		leakCheck: LeakCheckConfig{
+			Enabled: true,
+			Timeout: 50 * time.Millisecond,
+			Ignore:  []string{"github.com/arquivei/foundationkit/app.leakingConsumer"},
+		},
+		goroutineBaseline: newGoroutineBaseline(),
+	}
+
+	leak := make(chan struct{})
+	defer close(leak)
+	go leakingConsumer(leak)
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	assert.Empty(t, a.LastShutdownReport().LeakedGoroutines)
+}
+
+func TestShutdown_LeakCheckDisabled(t *testing.T) {
+	a := &App{}
+
+	leak := make(chan struct{})
+	defer close(leak)
+	go leakingConsumer(leak)
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	assert.Nil(t, a.LastShutdownReport().LeakedGoroutines)
+}
+
+func leakingConsumer(c chan struct{}) {
+	<-c
+}
+
+func TestParseGoroutine(t *testing.T) {
+	g, ok := parseGoroutine(`goroutine 7 [select, 2 minutes]:
+main.consume(0xc000010000)
+	/src/main.go:42 +0x1d
+created by main.main in goroutine 1
+	/src/main.go:12 +0x2a`)
+	require.True(t, ok)
+	assert.Equal(t, 7, g.ID)
+	assert.Equal(t, "select", g.State)
+	assert.Equal(t, "main.consume", g.Function)
+	assert.Equal(t, "main.main", g.CreatedBy)
+	assert.Contains(t, g.Stack, "/src/main.go:42")
+
+	_, ok = parseGoroutine("not a goroutine")
+	assert.False(t, ok)
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// LogLevelStatus is the current global log level and its pending revert, if any.
+type LogLevelStatus struct {
+	Level    string     `json:"level"`
+	RevertTo string     `json:"revert_to,omitempty"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// logLevel controls the temporary changes of the global log level.
+var logLevel struct {
+	mu sync.Mutex
+	// generation is incremented on every change so a timer can tell if it
+	// was replaced while waiting for the lock.
+	generation uint64
+	timer      *time.Timer
+	revertTo   zerolog.Level
+	revertAt   time.Time
+}
+
+// SetLogLevel changes the global log level. If ttl is greater than zero, the
+// level is reverted to the one before the change when the ttl expires. Any
+// pending revert is replaced by the new change. Every change is logged.
+func SetLogLevel(level zerolog.Level, ttl time.Duration) {
+	logLevel.mu.Lock()
+	defer logLevel.mu.Unlock()
+
+	previous := zerolog.GlobalLevel()
+	revertTo := previous
+	if logLevel.timer != nil {
+		// Keep reverting to the level set before the first temporary change.
+		logLevel.timer.Stop()
+		logLevel.timer = nil
+		revertTo = logLevel.revertTo
+	}
+	logLevel.generation++
+	logLevel.revertAt = time.Time{}
+
+	if ttl > 0 {
+		generation := logLevel.generation
+		logLevel.revertTo = revertTo
+		logLevel.revertAt = time.Now().Add(ttl)
+		logLevel.timer = time.AfterFunc(ttl, func() { revertLogLevel(generation) })
+	}
+
+	zerolog.SetGlobalLevel(level)
+	log.Log().
+		Str("log_level_from", previous.String()).
+		Str("log_level_to", level.String()).
+		Dur("log_level_ttl", ttl).
+		Msg("[app] Log level changed.")
+}
+
+func revertLogLevel(generation uint64) {
+	logLevel.mu.Lock()
+	defer logLevel.mu.Unlock()
+
+	if generation != logLevel.generation {
+		return
+	}
+	logLevel.generation++
+	logLevel.timer = nil
+	logLevel.revertAt = time.Time{}
+
+	previous := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(logLevel.revertTo)
+	log.Log().
+		Str("log_level_from", previous.String()).
+		Str("log_level_to", logLevel.revertTo.String()).
+		Msg("[app] Log level reverted after ttl expired.")
+}
+
+// GetLogLevel returns the global log level and its pending revert, if any.
+func GetLogLevel() LogLevelStatus {
+	logLevel.mu.Lock()
+	defer logLevel.mu.Unlock()
+
+	status := LogLevelStatus{
+		Level: zerolog.GlobalLevel().String(),
+	}
+	if logLevel.timer != nil {
+		revertAt := logLevel.revertAt
+		status.RevertTo = logLevel.revertTo.String()
+		status.RevertAt = &revertAt
+	}
+	return status
+}
+
+// newLogLevelHandler returns a handler that replies with the log level status
+// on GET and changes the log level on PUT. The new level is given by the
+// "level" parameter and the optional "ttl" parameter is a duration like 5m.
+func newLogLevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := setLogLevelFromRequest(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GetLogLevel())
+	})
+}
+
+func setLogLevelFromRequest(r *http.Request) error {
+	const op = errors.Op("app.setLogLevelFromRequest")
+
+	level, err := zerolog.ParseLevel(strings.ToLower(r.FormValue("level")))
+	if err != nil || r.FormValue("level") == "" {
+		return errors.E(op, "invalid log level", errors.KV("level", r.FormValue("level")))
+	}
+
+	var ttl time.Duration
+	if s := r.FormValue("ttl"); s != "" {
+		ttl, err = time.ParseDuration(s)
+		if err != nil || ttl < 0 {
+			return errors.E(op, "invalid ttl", errors.KV("ttl", s))
+		}
+	}
+
+	SetLogLevel(level, ttl)
+	return nil
+}
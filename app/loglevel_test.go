@@ -0,0 +1,88 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetLogLevel(t *testing.T) {
+	t.Helper()
+	level := zerolog.GlobalLevel()
+	t.Cleanup(func() { SetLogLevel(level, 0) })
+}
+
+func TestSetLogLevel_TTL(t *testing.T) {
+	resetLogLevel(t)
+	SetLogLevel(zerolog.InfoLevel, 0)
+
+	SetLogLevel(zerolog.DebugLevel, time.Hour)
+	SetLogLevel(zerolog.ErrorLevel, 50*time.Millisecond)
+
+	status := GetLogLevel()
+	assert.Equal(t, "error", status.Level)
+	assert.Equal(t, "info", status.RevertTo, "must revert to the level before the first temporary change")
+	require.NotNil(t, status.RevertAt)
+
+	assert.Eventually(t, func() bool {
+		return zerolog.GlobalLevel() == zerolog.InfoLevel
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, LogLevelStatus{Level: "info"}, GetLogLevel())
+}
+
+func TestSetLogLevel_CancelRevert(t *testing.T) {
+	resetLogLevel(t)
+	SetLogLevel(zerolog.InfoLevel, 0)
+
+	SetLogLevel(zerolog.DebugLevel, 20*time.Millisecond)
+	SetLogLevel(zerolog.WarnLevel, 0)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, LogLevelStatus{Level: "warn"}, GetLogLevel())
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	resetLogLevel(t)
+	SetLogLevel(zerolog.InfoLevel, 0)
+	handler := newLogLevelHandler()
+
+	serve := func(method, target string) (int, LogLevelStatus, string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		var status LogLevelStatus
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		}
+		return w.Code, status, strings.TrimSpace(w.Body.String())
+	}
+
+	code, status, _ := serve(http.MethodGet, "/debug/loglevel")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, LogLevelStatus{Level: "info"}, status)
+
+	code, status, _ = serve(http.MethodPut, "/debug/loglevel?level=DEBUG&ttl=5m")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "debug", status.Level)
+	assert.Equal(t, "info", status.RevertTo)
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+
+	code, _, body := serve(http.MethodPut, "/debug/loglevel?level=verbose")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "app.setLogLevelFromRequest: invalid log level [level=verbose]", body)
+
+	code, _, body = serve(http.MethodPut, "/debug/loglevel?level=info&ttl=soon")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "app.setLogLevelFromRequest: invalid ttl [ttl=soon]", body)
+
+	code, _, _ = serve(http.MethodPost, "/debug/loglevel?level=info")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel(), "invalid requests must not change the level")
+}
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/arquivei/foundationkit/retrier"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+// RestartMode specifies when a supervised loop should be restarted
+type RestartMode int
+
+const (
+	// RestartNever never restarts the loop. This is the default.
+	RestartNever RestartMode = iota
+	// RestartOnFailure restarts the loop if it returns an error or panics.
+	RestartOnFailure
+	// RestartAlways restarts the loop whenever it finishes.
+	RestartAlways
+)
+
+// RestartModeString returns a string representation of a RestartMode. This was intended for logging purposes.
+func RestartModeString(m RestartMode) string {
+	switch m {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on_failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return ""
+	}
+}
+
+// RestartPolicy configures how a loop registered with App.Go is supervised.
+type RestartPolicy struct {
+	// Mode specifies when the loop is restarted.
+	Mode RestartMode
+	// MaxRestarts limits how many times the loop is restarted. Zero means no limit.
+	MaxRestarts int
+	// Backoff is how much to wait before the first restart. It's doubled on
+	// each consecutive restart and reset when the loop returns without error
+	// or after it runs for at least MaxBackoff. Defaults to 50ms.
+	Backoff time.Duration
+	// MaxBackoff caps the time waited between restarts. Defaults to 30s.
+	MaxBackoff time.Duration
+	// Critical makes the app shutdown when the loop finishes and is not going
+	// to be restarted anymore.
+	Critical bool
+}
+
+// defaultMaxBackoff caps the restart backoff when RestartPolicy.MaxBackoff is not set.
+const defaultMaxBackoff = 30 * time.Second
+
+var (
+	loopRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "fkit",
+		Subsystem: "app",
+		Name:      "loop_running",
+		Help:      "Whether a supervised loop is running (1) or not (0).",
+	}, []string{"loop"})
+	loopExits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fkit",
+		Subsystem: "app",
+		Name:      "loop_exits_total",
+		Help:      "Total amount of times a supervised loop finished, by error code.",
+	}, []string{"loop", "error_code"})
+	loopRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fkit",
+		Subsystem: "app",
+		Name:      "loop_restarts_total",
+		Help:      "Total amount of times a supervised loop was restarted.",
+	}, []string{"loop"})
+)
+
+type supervisedLoop struct {
+	name    string
+	loop    MainLoopFunc
+	policy  RestartPolicy
+	probe   Probe
+	backoff *retrier.ExponentialBackoffCalculator
+}
+
+// Go registers a loop that runs supervised alongside the main loop. Loops are
+// started by RunAndWait, or right away if the app is already running.
+//
+// Each loop has its own readiness probe named "fkit/loop/<name>", which is ok
+// while the loop runs. Panics are recovered and handled as errors. The loop is
+// restarted according to policy and, if it's critical, the app shuts down
+// when the loop finishes for good. Loops are not restarted once the shutdown starts.
+func (a *App) Go(name string, loop MainLoopFunc, policy RestartPolicy) {
+	if loop == nil {
+		panic("Loop must not be nil")
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+
+	l := &supervisedLoop{
+		name:   name,
+		loop:   loop,
+		policy: policy,
+		probe:  a.Ready.MustNewProbe("fkit/loop/"+name, false),
+		backoff: retrier.NewExponentialBackoffCalculator(retrier.ExponentialBackoffCalculatorSettings{
+			BaseBackoff: policy.Backoff,
+		}),
+	}
+
+	log.Trace().
+		Str("loop", name).
+		Str("restart_mode", RestartModeString(policy.Mode)).
+		Bool("critical", policy.Critical).
+		Msg("[app] Loop registered.")
+
+	a.loopsMu.Lock()
+	defer a.loopsMu.Unlock()
+
+	a.loops = append(a.loops, l)
+	if a.loopsCtx != nil {
+		go a.supervise(a.loopsCtx, l, a.loopsErrs)
+	}
+}
+
+// startLoops starts all registered loops. Loops stop being restarted when ctx is canceled.
+// Critical loops report through errs when they finish.
+func (a *App) startLoops(ctx context.Context, errs chan<- error) {
+	a.loopsMu.Lock()
+	defer a.loopsMu.Unlock()
+
+	a.loopsCtx = ctx
+	a.loopsErrs = errs
+	for _, l := range a.loops {
+		go a.supervise(ctx, l, errs)
+	}
+}
+
+func (a *App) hasLoops() bool {
+	a.loopsMu.Lock()
+	defer a.loopsMu.Unlock()
+	return len(a.loops) > 0
+}
+
+func (a *App) supervise(ctx context.Context, l *supervisedLoop, errs chan<- error) {
+	const op = errors.Op("app.App.supervise")
+
+	logger := a.logger.With().Str("loop", l.name).Logger()
+
+	// attempt counts the consecutive restarts, to grow the backoff only while
+	// the loop keeps failing.
+	attempt := 0
+	for restarts := 0; ; restarts++ {
+		logger.Info().Int("restarts", restarts).Msg("Loop starting now!")
+		l.probe.SetOk()
+		loopRunning.WithLabelValues(l.name).Set(1)
+
+		started := a.clock().Now()
+		err := errors.E(op, runLoop(l.loop), errors.KV("loop", l.name))
+
+		loopRunning.WithLabelValues(l.name).Set(0)
+		loopExits.WithLabelValues(l.name, errors.GetCode(err).String()).Inc()
+
+		if ctx.Err() != nil {
+			logger.Info().Err(err).Msg("Loop finished during shutdown.")
+			return
+		}
+
+		if !l.shouldRestart(err, restarts) {
+			if err != nil {
+				l.probe.SetError(err)
+				logger.Error().Err(err).Msg("Loop failed and won't be restarted.")
+			} else {
+				logger.Info().Msg("Loop finished and won't be restarted.")
+			}
+			if l.policy.Critical {
+				select {
+				case errs <- err:
+				case <-ctx.Done():
+				}
+			}
+			return
+		}
+
+		if err == nil || a.clock().Now().Sub(started) >= l.policy.MaxBackoff {
+			attempt = 0
+		}
+		attempt++
+		backoff := l.backoffFor(attempt)
+		if err != nil {
+			l.probe.SetError(err)
+		} else {
+			l.probe.SetNotOk()
+		}
+		logger.Warn().Err(err).Dur("backoff", backoff).Msg("Loop finished, restarting after backoff.")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.clock().After(ctx, backoff):
+		}
+		loopRestarts.WithLabelValues(l.name).Inc()
+	}
+}
+
+func (l *supervisedLoop) shouldRestart(err error, restarts int) bool {
+	if l.policy.MaxRestarts > 0 && restarts >= l.policy.MaxRestarts {
+		return false
+	}
+	switch l.policy.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+func (l *supervisedLoop) backoffFor(attempt int) time.Duration {
+	backoff := l.backoff.CalculateBackoff(attempt)
+	// A non positive backoff means the calculation overflowed.
+	if backoff > l.policy.MaxBackoff || backoff <= 0 {
+		return l.policy.MaxBackoff
+	}
+	return backoff
+}
+
+func runLoop(loop MainLoopFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.NewFromRecover(r)
+		}
+	}()
+	return loop()
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGo_RestartOnFailure(t *testing.T) {
+	a := newTestApp(t)
+
+	var runs atomic.Int32
+	a.Go("flaky", func() error {
+		if runs.Add(1) == 3 {
+			panic("third time is not a charm")
+		}
+		return errors.New("flaky failure")
+	}, RestartPolicy{
+		Mode:        RestartOnFailure,
+		MaxRestarts: 2,
+		Backoff:     time.Millisecond,
+		Critical:    true,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.RunAndWait(nil)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("critical loop should have triggered the shutdown")
+	}
+	assert.Equal(t, int32(3), runs.Load())
+	assert.False(t, a.mainHealthnessProbe.IsOk())
+}
+
+func TestGo_NonCriticalDoesNotShutdown(t *testing.T) {
+	a := newTestApp(t)
+
+	a.Go("one-shot", func() error {
+		return errors.New("one-shot failure")
+	}, RestartPolicy{})
+
+	finish := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.RunAndWait(func() error {
+			<-finish
+			return nil
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, cause := a.Ready.CheckProbes()
+		return cause == "fkit/loop/one-shot (app.App.supervise: one-shot failure [loop=one-shot])"
+	}, time.Second, time.Millisecond)
+	assert.True(t, a.mainHealthnessProbe.IsOk(), "app must keep running")
+
+	close(finish)
+	<-done
+}
+
+// backoffRecorder is a Clock that records the backoffs without waiting.
+type backoffRecorder struct {
+	mu       sync.Mutex
+	backoffs []time.Duration
+}
+
+func (c *backoffRecorder) Now() time.Time { return time.Now() }
+
+func (c *backoffRecorder) After(_ context.Context, d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.backoffs = append(c.backoffs, d)
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+func TestGo_BackoffResetsAfterCleanReturn(t *testing.T) {
+	a := newTestApp(t)
+	clock := &backoffRecorder{}
+	a.Clock = clock
+
+	var runs atomic.Int32
+	a.Go("scheduler", func() error {
+		if runs.Add(1) == 4 {
+			return nil
+		}
+		return errors.New("failure")
+	}, RestartPolicy{
+		Mode:        RestartAlways,
+		MaxRestarts: 6,
+		Backoff:     10 * time.Millisecond,
+		MaxBackoff:  25 * time.Millisecond,
+		Critical:    true,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.RunAndWait(nil)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("critical loop should have triggered the shutdown")
+	}
+
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		25 * time.Millisecond,
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		25 * time.Millisecond,
+	}, clock.backoffs[:6])
+}
+
+func TestSupervisedLoop_BackoffFor(t *testing.T) {
+	a := newTestApp(t)
+	a.Go("overflow", func() error { return nil }, RestartPolicy{})
+	l := a.loops[0]
+
+	assert.Equal(t, defaultMaxBackoff, l.policy.MaxBackoff)
+	assert.Equal(t, 50*time.Millisecond, l.backoffFor(1))
+	assert.Equal(t, defaultMaxBackoff, l.backoffFor(20))
+	assert.Equal(t, defaultMaxBackoff, l.backoffFor(2000), "overflowed backoff must be capped")
+}
+
+func TestSupervisedLoop_ShouldRestart(t *testing.T) {
+	failure := errors.New("failure")
+	for _, tc := range []struct {
+		name     string
+		policy   RestartPolicy
+		err      error
+		restarts int
+		expected bool
+	}{
+		{"never", RestartPolicy{Mode: RestartNever}, failure, 0, false},
+		{"on failure with error", RestartPolicy{Mode: RestartOnFailure}, failure, 0, true},
+		{"on failure without error", RestartPolicy{Mode: RestartOnFailure}, nil, 0, false},
+		{"always", RestartPolicy{Mode: RestartAlways}, nil, 10, true},
+		{"max restarts reached", RestartPolicy{Mode: RestartAlways, MaxRestarts: 3}, nil, 3, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &supervisedLoop{policy: tc.policy}
+			assert.Equal(t, tc.expected, l.shouldRestart(tc.err, tc.restarts))
+		})
+	}
+}
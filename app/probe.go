@@ -1,65 +1,144 @@
 package app
 
 import (
+	"context"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/arquivei/foundationkit/errors"
+	"github.com/rs/zerolog/log"
 )
 
 // Probe stores the state of a probe (`true` or `false` for ok and not ok respectively).
 type Probe struct {
-	ok *bool
+	state *probeState
 }
 
 // Set changes the state of a probe. Use `true` for ok and `false` for not ok.
 func (p *Probe) Set(ok bool) {
-	*p.ok = ok
+	p.state.set(ok, nil)
 }
 
 // SetOk sets the probe as ok. Same as `Set(true)`.
 func (p *Probe) SetOk() {
-	*p.ok = true
+	p.state.set(true, nil)
 }
 
 // SetNotOk sets the probe as not ok. Same as `Set(false)`.
 func (p *Probe) SetNotOk() {
-	*p.ok = false
+	p.state.set(false, nil)
+}
+
+// SetError sets the probe as not ok, using err as the reason. If err is nil,
+// the probe is set as ok.
+func (p *Probe) SetError(err error) {
+	p.state.set(err == nil, err)
 }
 
 // IsOk returns the state of the probe  (`true` or `false` for ok and not ok respectively).
 func (p *Probe) IsOk() bool {
-	return *p.ok
+	ok, _ := p.state.get()
+	return ok
+}
+
+// probeState is the shared state between a Probe and its ProbeGroup.
+type probeState struct {
+	mu    sync.RWMutex
+	ok    bool
+	err   error
+	since time.Time
+}
+
+func newProbeState(ok bool) *probeState {
+	return &probeState{
+		ok:    ok,
+		since: time.Now(),
+	}
+}
+
+func (s *probeState) set(ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ok != ok {
+		s.since = time.Now()
+	}
+	s.ok = ok
+	s.err = err
+}
+
+func (s *probeState) status(name string) ProbeStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := ProbeStatus{
+		Name:           name,
+		Ok:             s.ok,
+		LastTransition: s.since,
+	}
+	if !s.ok && s.err != nil {
+		status.Reason = s.err.Error()
+	}
+	return status
+}
+
+// ProbeStatus is a snapshot of the state of a probe.
+type ProbeStatus struct {
+	// Name is the name of the probe.
+	Name string `json:"name"`
+	// Ok is the state of the probe.
+	Ok bool `json:"ok"`
+	// LastTransition is when the probe last changed between ok and not ok.
+	LastTransition time.Time `json:"last_transition"`
+	// Reason is the error that made the probe not ok, if any.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProbeGroupStatus is a snapshot of the state of all probes in a group.
+type ProbeGroupStatus struct {
+	// Ok is true if all probes are ok.
+	Ok bool `json:"ok"`
+	// Probes are sorted by name.
+	Probes []ProbeStatus `json:"probes"`
+}
+
+func (s *probeState) get() (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ok, s.err
 }
 
+// CheckFunc is a function that actively checks if something is ok. It should
+// return nil if it's ok or an error explaining why it's not.
+type CheckFunc func(context.Context) error
+
 // ProbeGroup aggregates and manages probes.
 type ProbeGroup struct {
 	lock   *sync.RWMutex
-	probes map[string]*bool
+	probes map[string]*probeState
+
+	stopChecks     chan struct{}
+	stopChecksOnce *sync.Once
 }
 
 // NewProbeGroup returns a new ProbeGroup.
 func NewProbeGroup() ProbeGroup {
 	return ProbeGroup{
-		lock:   &sync.RWMutex{},
-		probes: make(map[string]*bool),
+		lock:           &sync.RWMutex{},
+		probes:         make(map[string]*probeState),
+		stopChecks:     make(chan struct{}),
+		stopChecksOnce: &sync.Once{},
 	}
 }
 
 // NewProbe returns a new Probe with the given name.
 func (m *ProbeGroup) NewProbe(name string, ok bool) (Probe, error) {
-	if _, ok := m.probes[name]; ok {
-		return Probe{}, errors.Errorf("probe '%s' already registered", name)
-	}
-
-	if err := m.checkName(name); err != nil {
+	state, err := m.newProbeState(name, ok)
+	if err != nil {
 		return Probe{}, err
 	}
-
-	s := ok
-	m.probes[name] = &s
-	return Probe{&s}, nil
+	return Probe{state}, nil
 }
 
 // MustNewProbe returns a new Probe with the given name and panics in case of error.
@@ -71,6 +150,105 @@ func (m *ProbeGroup) MustNewProbe(name string, ok bool) Probe {
 	return p
 }
 
+func (m *ProbeGroup) newProbeState(name string, ok bool) (*probeState, error) {
+	if err := m.checkName(name); err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.probes[name]; ok {
+		return nil, errors.Errorf("probe '%s' already registered", name)
+	}
+
+	state := newProbeState(ok)
+	m.probes[name] = state
+	return state, nil
+}
+
+// AddCheck registers a probe with the given name that is updated by running
+// check on the background every interval. Each run is canceled after timeout,
+// if timeout is greater than zero.
+//
+// The probe starts as not ok until the first run finishes, which happens right
+// away. The result and error of the last run are cached and reported by the
+// group until the next run finishes. Checks run until StopChecks is called.
+func (m *ProbeGroup) AddCheck(name string, check CheckFunc, interval, timeout time.Duration) error {
+	const op = errors.Op("app.ProbeGroup.AddCheck")
+
+	if check == nil {
+		return errors.E(op, "check is nil", errors.KV("probe", name))
+	}
+	if interval <= 0 {
+		return errors.E(op, "interval must be greater than zero", errors.KV("probe", name))
+	}
+
+	state, err := m.newProbeState(name, false)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	state.set(false, errors.New("check has not finished yet"))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			err := runCheck(check, timeout)
+			state.set(err == nil, err)
+			if err != nil {
+				log.Trace().Err(err).Str("probe", name).Msg("[app] Probe check failed.")
+			}
+
+			select {
+			case <-m.stopChecks:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// MustAddCheck calls AddCheck and panics in case of error.
+func (m *ProbeGroup) MustAddCheck(name string, check CheckFunc, interval, timeout time.Duration) {
+	if err := m.AddCheck(name, check, interval, timeout); err != nil {
+		panic(err)
+	}
+}
+
+// StopChecks stops scheduling the checks added with AddCheck. A check that is
+// already running is not interrupted. Probes keep reporting the last cached result.
+// It is called by the app at the end of the shutdown.
+func (m *ProbeGroup) StopChecks() {
+	// A zero value group has no checks
+	if m.stopChecksOnce == nil {
+		return
+	}
+	m.stopChecksOnce.Do(func() {
+		close(m.stopChecks)
+	})
+}
+
+func runCheck(check CheckFunc, timeout time.Duration) (err error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	panicErr := errors.DontPanic(func() {
+		err = check(ctx)
+	})
+	if panicErr != nil {
+		return panicErr
+	}
+	return err
+}
+
 var reIsValidProbeName = regexp.MustCompile("[a-zA-Z0-9_/-]{3,}")
 
 func (ProbeGroup) checkName(name string) error {
@@ -80,23 +258,56 @@ func (ProbeGroup) checkName(name string) error {
 	return nil
 }
 
+// Status returns a snapshot of the state of all probes, sorted by name.
+func (m *ProbeGroup) Status() ProbeGroupStatus {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	status := ProbeGroupStatus{
+		Ok:     true,
+		Probes: make([]ProbeStatus, 0, len(m.probes)),
+	}
+	for name, state := range m.probes {
+		probeStatus := state.status(name)
+		if !probeStatus.Ok {
+			status.Ok = false
+		}
+		status.Probes = append(status.Probes, probeStatus)
+	}
+	sort.Slice(status.Probes, func(i, j int) bool {
+		return status.Probes[i].Name < status.Probes[j].Name
+	})
+
+	return status
+}
+
 // CheckProbes range through the probes and returns the state of the group.
 // If any probe is not ok (false) the group state is not ok (false).
 // If the group is not ok, it's also returned the cause in the second return parameter.
-// If more than one probe is not ok, the causes are concatenated by a comma.
+// If more than one probe is not ok, the causes are concatenated by a comma, sorted by name.
+// If the probe has a reason to be not ok, it is appended to the name.
 func (m *ProbeGroup) CheckProbes() (bool, string) {
-	ok := true
+	status := m.Status()
+	return status.Ok, status.cause()
+}
+
+func (s ProbeGroupStatus) cause() string {
 	cause := strings.Builder{}
 
-	for name, probeOk := range m.probes {
-		if !*probeOk {
-			ok = false
-			if cause.Len() > 0 {
-				cause.WriteString(",")
-			}
-			cause.WriteString(name)
+	for _, probe := range s.Probes {
+		if probe.Ok {
+			continue
+		}
+		if cause.Len() > 0 {
+			cause.WriteString(",")
+		}
+		cause.WriteString(probe.Name)
+		if probe.Reason != "" {
+			cause.WriteString(" (")
+			cause.WriteString(probe.Reason)
+			cause.WriteString(")")
 		}
 	}
 
-	return ok, cause.String()
+	return cause.String()
 }
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeGroup_NewProbe(t *testing.T) {
+	g := NewProbeGroup()
+
+	p := g.MustNewProbe("my-probe", true)
+	ok, cause := g.CheckProbes()
+	assert.True(t, ok)
+	assert.Empty(t, cause)
+
+	p.SetNotOk()
+	ok, cause = g.CheckProbes()
+	assert.False(t, ok)
+	assert.Equal(t, "my-probe", cause)
+
+	_, err := g.NewProbe("my-probe", true)
+	assert.EqualError(t, err, "probe 'my-probe' already registered")
+}
+
+func TestProbeGroup_AddCheck(t *testing.T) {
+	g := NewProbeGroup()
+	defer g.StopChecks()
+
+	var fail atomic.Bool
+	require.NoError(t, g.AddCheck("my-check", func(context.Context) error {
+		if fail.Load() {
+			return errors.New("database is down")
+		}
+		return nil
+	}, 10*time.Millisecond, time.Second))
+
+	assert.Eventually(t, func() bool {
+		ok, _ := g.CheckProbes()
+		return ok
+	}, time.Second, time.Millisecond)
+
+	fail.Store(true)
+	assert.Eventually(t, func() bool {
+		_, cause := g.CheckProbes()
+		return cause == "my-check (database is down)"
+	}, time.Second, time.Millisecond)
+}
+
+func TestProbeGroup_AddCheck_Timeout(t *testing.T) {
+	g := NewProbeGroup()
+	defer g.StopChecks()
+
+	require.NoError(t, g.AddCheck("slow-check", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Hour, time.Millisecond))
+
+	assert.Eventually(t, func() bool {
+		_, cause := g.CheckProbes()
+		return cause == "slow-check (context deadline exceeded)"
+	}, time.Second, time.Millisecond)
+}
+
+func TestProbeGroup_AddCheck_Panic(t *testing.T) {
+	g := NewProbeGroup()
+	defer g.StopChecks()
+
+	require.NoError(t, g.AddCheck("panic-check", func(context.Context) error {
+		panic("boom")
+	}, time.Hour, 0))
+
+	assert.Eventually(t, func() bool {
+		_, cause := g.CheckProbes()
+		return strings.HasPrefix(cause, "panic-check (") && strings.Contains(cause, "panic: boom")
+	}, time.Second, time.Millisecond)
+}
+
+func TestProbeGroup_AddCheck_InvalidArgs(t *testing.T) {
+	g := NewProbeGroup()
+
+	assert.Error(t, g.AddCheck("my-check", nil, time.Second, 0))
+	assert.Error(t, g.AddCheck("my-check", func(context.Context) error { return nil }, 0, 0))
+
+	g.MustNewProbe("my-check", true)
+	assert.Error(t, g.AddCheck("my-check", func(context.Context) error { return nil }, time.Second, 0))
+}
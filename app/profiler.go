@@ -0,0 +1,293 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+// ProfilerConfig configures the background profiler started by App.StartProfiler.
+type ProfilerConfig struct {
+	// Enabled starts the profiler. It's opt-in.
+	Enabled bool `default:"false"`
+	// Dir is the directory where the profiles are written. It's created if needed.
+	Dir string `default:"profiles"`
+	// Interval is the time between periodic snapshots. Zero disables them.
+	Interval time.Duration `default:"10m"`
+	// CPUDuration is for how long the CPU is profiled on each snapshot.
+	CPUDuration time.Duration `default:"10s"`
+	// MutexProfileFraction is set with runtime.SetMutexProfileFraction if
+	// mutex profiling is not enabled yet.
+	MutexProfileFraction int `default:"5"`
+	// MemoryThresholdMB triggers a snapshot when the heap in use crosses it.
+	// Zero disables it.
+	MemoryThresholdMB uint64 `default:"0"`
+	// MemoryCheckInterval is how often the heap is checked against MemoryThresholdMB.
+	MemoryCheckInterval time.Duration `default:"10s"`
+	// MaxFiles is how many profile files are kept. Zero means no limit.
+	MaxFiles int `default:"100"`
+	// MaxSizeMB is how many megabytes of profile files are kept. Zero means no limit.
+	MaxSizeMB int64 `default:"100"`
+}
+
+// NewDefaultProfilerConfig returns a disabled ProfilerConfig with the default values.
+func NewDefaultProfilerConfig() ProfilerConfig {
+	return ProfilerConfig{
+		Dir:                  "profiles",
+		Interval:             10 * time.Minute,
+		CPUDuration:          10 * time.Second,
+		MutexProfileFraction: 5,
+		MemoryCheckInterval:  10 * time.Second,
+		MaxFiles:             100,
+		MaxSizeMB:            100,
+	}
+}
+
+// Profiler snapshot triggers.
+const (
+	profilerTriggerInterval = "interval"
+	profilerTriggerMemory   = "memory"
+	profilerTriggerSignal   = "signal"
+)
+
+// profileExt is the extension of the files written by the profiler.
+const profileExt = ".pprof"
+
+var profilerSnapshots = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "fkit",
+	Subsystem: "app",
+	Name:      "profiler_snapshots_total",
+	Help:      "Total amount of profiling snapshots taken, by trigger.",
+}, []string{"trigger"})
+
+type profiler struct {
+	config ProfilerConfig
+	// mu serializes snapshots.
+	mu sync.Mutex
+	// heapInUse returns the bytes of heap in use. It's replaceable for tests.
+	heapInUse func() uint64
+}
+
+// StartProfiler starts a background profiler that writes CPU, heap, goroutine
+// and mutex profiles to config.Dir periodically, when the heap in use crosses
+// config.MemoryThresholdMB and when the process receives a SIGUSR1. Old files
+// are removed to respect the MaxFiles and MaxSizeMB limits.
+//
+// It does nothing if config.Enabled is false. The profiler is stopped by a
+// shutdown handler.
+func (a *App) StartProfiler(config ProfilerConfig) error {
+	const op = errors.Op("app.App.StartProfiler")
+
+	if !config.Enabled {
+		return nil
+	}
+	if err := os.MkdirAll(config.Dir, 0o750); err != nil {
+		return errors.E(op, err)
+	}
+	if runtime.SetMutexProfileFraction(-1) == 0 && config.MutexProfileFraction > 0 {
+		runtime.SetMutexProfileFraction(config.MutexProfileFraction)
+	}
+
+	p := &profiler{
+		config:    config,
+		heapInUse: readHeapInUse,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.run(ctx)
+	}()
+
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:     "fkit/app/profiler",
+		Priority: ShutdownPriority(0),
+		Policy:   ErrorPolicyWarn,
+		Handler: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	log.Trace().
+		Str("dir", config.Dir).
+		Dur("interval", config.Interval).
+		Uint64("memory_threshold_mb", config.MemoryThresholdMB).
+		Msg("[app] Profiler started.")
+
+	return nil
+}
+
+func (p *profiler) run(ctx context.Context) {
+	sigusr1 := make(chan os.Signal, 1)
+	signal.Notify(sigusr1, syscall.SIGUSR1)
+	defer signal.Stop(sigusr1)
+
+	var interval, memoryCheck <-chan time.Time
+	if p.config.Interval > 0 {
+		t := time.NewTicker(p.config.Interval)
+		defer t.Stop()
+		interval = t.C
+	}
+	if p.config.MemoryThresholdMB > 0 && p.config.MemoryCheckInterval > 0 {
+		t := time.NewTicker(p.config.MemoryCheckInterval)
+		defer t.Stop()
+		memoryCheck = t.C
+	}
+
+	aboveThreshold := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-interval:
+			p.snapshot(ctx, profilerTriggerInterval)
+		case <-sigusr1:
+			p.snapshot(ctx, profilerTriggerSignal)
+		case <-memoryCheck:
+			// Only crossing the threshold triggers a snapshot, staying above it doesn't.
+			above := p.heapInUse() >= p.config.MemoryThresholdMB*1024*1024
+			if above && !aboveThreshold {
+				p.snapshot(ctx, profilerTriggerMemory)
+			}
+			aboveThreshold = above
+		}
+	}
+}
+
+// snapshot writes all the profiles and applies the retention. Failures are
+// logged and don't stop the other profiles from being written.
+func (p *profiler) snapshot(ctx context.Context, trigger string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prefix := filepath.Join(p.config.Dir, time.Now().UTC().Format("20060102T150405.000Z")+"-"+trigger+"-")
+	logger := log.With().Str("trigger", trigger).Logger()
+
+	for _, name := range []string{"heap", "goroutine", "mutex"} {
+		if err := writeProfile(prefix+name+profileExt, func(f *os.File) error {
+			return pprof.Lookup(name).WriteTo(f, 0)
+		}); err != nil {
+			logger.Error().Err(err).Str("profile", name).Msg("[app] Failed to write profile.")
+		}
+	}
+
+	if p.config.CPUDuration > 0 {
+		if err := writeProfile(prefix+"cpu"+profileExt, func(f *os.File) error {
+			return profileCPU(ctx, f, p.config.CPUDuration)
+		}); err != nil {
+			logger.Error().Err(err).Str("profile", "cpu").Msg("[app] Failed to write profile.")
+		}
+	}
+
+	if err := p.applyRetention(); err != nil {
+		logger.Error().Err(err).Msg("[app] Failed to apply profiles retention.")
+	}
+
+	profilerSnapshots.WithLabelValues(trigger).Inc()
+	logger.Info().Str("dir", p.config.Dir).Msg("Profiling snapshot written.")
+}
+
+func writeProfile(path string, write func(f *os.File) error) error {
+	const op = errors.Op("app.writeProfile")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return errors.E(op, err, errors.KV("path", path))
+	}
+	return nil
+}
+
+// profileCPU profiles the CPU for d or until ctx is done. It fails if the CPU
+// is already being profiled, like by the /debug/pprof/profile endpoint.
+func profileCPU(ctx context.Context, f *os.File, d time.Duration) error {
+	if err := pprof.StartCPUProfile(f); err != nil {
+		return err
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+	pprof.StopCPUProfile()
+	return nil
+}
+
+// applyRetention removes the oldest profile files until the limits are respected.
+func (p *profiler) applyRetention() error {
+	const op = errors.Op("app.profiler.applyRetention")
+
+	entries, err := os.ReadDir(p.config.Dir)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	type profileFile struct {
+		path string
+		size int64
+	}
+	var files []profileFile
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), profileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, profileFile{path: filepath.Join(p.config.Dir, e.Name()), size: info.Size()})
+		total += info.Size()
+	}
+	// File names start with the time of the snapshot, so they sort from the oldest.
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	maxSize := p.config.MaxSizeMB * 1024 * 1024
+	var errs []error
+	for len(files) > 0 &&
+		((p.config.MaxFiles > 0 && len(files) > p.config.MaxFiles) || (maxSize > 0 && total > maxSize)) {
+		if err := os.Remove(files[0].path); err != nil {
+			errs = append(errs, err)
+		}
+		total -= files[0].size
+		files = files[1:]
+	}
+	if len(errs) > 0 {
+		return errors.E(op, errors.ConcatErrors(errs...))
+	}
+	return nil
+}
+
+func readHeapInUse() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapInuse
+}
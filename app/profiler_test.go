@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listProfiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestProfiler_Snapshot(t *testing.T) {
+	config := NewDefaultProfilerConfig()
+	config.Dir = t.TempDir()
+	config.CPUDuration = 10 * time.Millisecond
+	p := &profiler{config: config}
+
+	p.snapshot(context.Background(), profilerTriggerSignal)
+
+	names := listProfiles(t, config.Dir)
+	require.Len(t, names, 4)
+	for i, profile := range []string{"cpu", "goroutine", "heap", "mutex"} {
+		assert.True(t, strings.HasSuffix(names[i], "-signal-"+profile+profileExt), names[i])
+	}
+}
+
+func TestProfiler_Retention(t *testing.T) {
+	config := NewDefaultProfilerConfig()
+	config.Dir = t.TempDir()
+	config.MaxFiles = 10
+	config.MaxSizeMB = 1
+	p := &profiler{config: config}
+
+	writeConfigFile(t, config.Dir, "1-old"+profileExt, strings.Repeat("x", 600*1024))
+	writeConfigFile(t, config.Dir, "2-old"+profileExt, "x")
+	writeConfigFile(t, config.Dir, "3-new"+profileExt, strings.Repeat("x", 600*1024))
+	writeConfigFile(t, config.Dir, "4-new"+profileExt, "x")
+	writeConfigFile(t, config.Dir, "other.txt", "x")
+
+	require.NoError(t, p.applyRetention())
+	assert.Equal(t, []string{"2-old" + profileExt, "3-new" + profileExt, "4-new" + profileExt, "other.txt"},
+		listProfiles(t, config.Dir), "the oldest files must be removed until the size limit is respected")
+
+	p.config.MaxFiles = 2
+	require.NoError(t, p.applyRetention())
+	assert.Equal(t, []string{"3-new" + profileExt, "4-new" + profileExt, "other.txt"},
+		listProfiles(t, config.Dir), "the oldest files must be removed until the count limit is respected")
+}
+
+func TestProfiler_MemoryThreshold(t *testing.T) {
+	config := NewDefaultProfilerConfig()
+	config.Dir = t.TempDir()
+	config.Interval = 0
+	config.CPUDuration = 0
+	config.MemoryThresholdMB = 1
+	config.MemoryCheckInterval = time.Millisecond
+
+	heap := make(chan uint64)
+	p := &profiler{config: config, heapInUse: func() uint64 { return <-heap }}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.run(ctx)
+	}()
+
+	// Crossing the threshold takes a snapshot, staying above it doesn't.
+	for _, mb := range []uint64{0, 2, 3, 0} {
+		heap <- mb * 1024 * 1024
+	}
+	cancel()
+	<-done
+
+	assert.Len(t, listProfiles(t, config.Dir), 3)
+}
+
+func TestStartProfiler(t *testing.T) {
+	a := &App{}
+	require.NoError(t, a.StartProfiler(ProfilerConfig{Dir: filepath.Join(t.TempDir(), "disabled")}))
+	assert.Empty(t, a.shutdownHandlers, "a disabled profiler must not start")
+
+	config := NewDefaultProfilerConfig()
+	config.Enabled = true
+	config.Dir = filepath.Join(t.TempDir(), "profiles")
+	require.NoError(t, a.StartProfiler(config))
+	assert.DirExists(t, config.Dir)
+	assert.NoError(t, a.Shutdown(context.Background()))
+}
@@ -10,12 +10,20 @@ import (
 
 // Recover recovers from the panic and if panic persists, logs
 // it with trace error. Should be called at the first line in
-// the main function.
+// the main function. If SetupCrashReport was called with a path, a
+// crash report with the full stack is also written to it.
 func Recover() {
 	if r := recover(); r != nil {
-		log.Fatal().
+		stack := debug.Stack()
+		event := log.Fatal()
+		if path, err := writeCrashReport(r, stack); err != nil {
+			event = event.AnErr("crash_report_error", err)
+		} else if path != "" {
+			event = event.Str("crash_report", path)
+		}
+		event.
 			Err(errors.NewFromRecover(r)).
-			Str("panic_stack", stringsutil.Truncate(string(debug.Stack()), 1024)).
+			Str("panic_stack", stringsutil.Truncate(string(stack), 1024)).
 			Msg("[app] App terminated due to panic.")
 	}
 }
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/rs/zerolog"
+)
+
+// RuntimeConfig configures how the go runtime is tuned when the App is created.
+type RuntimeConfig struct {
+	// Tune sets GOMEMLIMIT from the cgroup memory limit. The GOMEMLIMIT
+	// environment variable takes precedence. GOMAXPROCS is left to the go
+	// runtime, which already follows the cgroup CPU quota.
+	Tune bool `default:"false"`
+	// MemoryLimitRatio is the fraction of the cgroup memory limit used as
+	// GOMEMLIMIT, leaving the rest as headroom for non heap memory.
+	MemoryLimitRatio float64 `default:"0.9"`
+	// Metrics exports all the go runtime/metrics on /metrics instead of
+	// only the default go collector metrics.
+	Metrics bool `default:"false"`
+}
+
+// NewDefaultRuntimeConfig returns a RuntimeConfig that doesn't change the runtime.
+func NewDefaultRuntimeConfig() RuntimeConfig {
+	return RuntimeConfig{
+		MemoryLimitRatio: 0.9,
+	}
+}
+
+// cgroupRoot is where the cgroup filesystem is mounted. It's expected to be
+// the container's own cgroup, as seen from inside a cgroup namespace.
+var cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupUnlimited is the threshold above which cgroup v1 limits mean no limit.
+const cgroupUnlimited = int64(1) << 62
+
+// cgroupLimits are the resource limits of the cgroup. Zero means no limit.
+type cgroupLimits struct {
+	cpu    float64
+	memory int64
+}
+
+// tuneRuntime applies config to the runtime and logs the decisions.
+func tuneRuntime(logger *zerolog.Logger, config RuntimeConfig) {
+	if config.Metrics {
+		registerRuntimeMetrics(logger)
+	}
+	if !config.Tune {
+		return
+	}
+
+	limits, err := readCgroupLimits(cgroupRoot)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to read cgroup limits, the runtime will not be tuned.")
+		return
+	}
+
+	// Setting GOMAXPROCS would stop the runtime from updating it when the
+	// CPU quota changes, so it's only logged.
+	logger.Info().
+		Float64("cgroup_cpu_quota", limits.cpu).
+		Int("gomaxprocs", runtime.GOMAXPROCS(0)).
+		Msg("GOMAXPROCS left to the go runtime.")
+
+	switch {
+	case os.Getenv("GOMEMLIMIT") != "":
+		logger.Info().Str("gomemlimit", os.Getenv("GOMEMLIMIT")).Msg("GOMEMLIMIT set by the environment.")
+	case limits.memory > 0:
+		memLimit := memoryLimitForCgroup(limits.memory, config.MemoryLimitRatio)
+		previous := debug.SetMemoryLimit(memLimit)
+		logger.Info().
+			Int64("cgroup_memory_limit", limits.memory).
+			Float64("memory_limit_ratio", config.MemoryLimitRatio).
+			Int64("gomemlimit", memLimit).
+			Int64("previous_gomemlimit", previous).
+			Msg("GOMEMLIMIT set from the cgroup memory limit.")
+	default:
+		logger.Info().Msg("No cgroup memory limit, GOMEMLIMIT unchanged.")
+	}
+}
+
+func memoryLimitForCgroup(limit int64, ratio float64) int64 {
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	return int64(float64(limit) * ratio)
+}
+
+// readCgroupLimits reads the CPU quota and memory limit from cgroup v2 or,
+// if not available, from cgroup v1.
+func readCgroupLimits(root string) (cgroupLimits, error) {
+	const op = errors.Op("app.readCgroupLimits")
+
+	var limits cgroupLimits
+	var err error
+	if fileExists(filepath.Join(root, "cgroup.controllers")) {
+		limits, err = readCgroupV2Limits(root)
+	} else {
+		limits, err = readCgroupV1Limits(root)
+	}
+	if err != nil {
+		return cgroupLimits{}, errors.E(op, err, errors.KV("root", root))
+	}
+	return limits, nil
+}
+
+func readCgroupV2Limits(root string) (cgroupLimits, error) {
+	var limits cgroupLimits
+
+	// cpu.max is "<quota> <period>", where quota may be "max"
+	cpuMax, ok, err := readCgroupFile(filepath.Join(root, "cpu.max"))
+	if err != nil {
+		return limits, err
+	}
+	if ok && len(cpuMax) == 2 && cpuMax[0] != "max" {
+		if limits.cpu, err = cpuQuota(cpuMax[0], cpuMax[1]); err != nil {
+			return limits, err
+		}
+	}
+
+	memoryMax, ok, err := readCgroupFile(filepath.Join(root, "memory.max"))
+	if err != nil {
+		return limits, err
+	}
+	if ok && len(memoryMax) == 1 && memoryMax[0] != "max" {
+		if limits.memory, err = strconv.ParseInt(memoryMax[0], 10, 64); err != nil {
+			return limits, err
+		}
+	}
+	return limits, nil
+}
+
+func readCgroupV1Limits(root string) (cgroupLimits, error) {
+	var limits cgroupLimits
+
+	quota, hasQuota, err := readCgroupFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return limits, err
+	}
+	period, hasPeriod, err := readCgroupFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return limits, err
+	}
+	// A quota of -1 means no limit
+	if hasQuota && hasPeriod && len(quota) == 1 && len(period) == 1 && quota[0] != "-1" {
+		if limits.cpu, err = cpuQuota(quota[0], period[0]); err != nil {
+			return limits, err
+		}
+	}
+
+	memoryLimit, ok, err := readCgroupFile(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+	if err != nil {
+		return limits, err
+	}
+	if ok && len(memoryLimit) == 1 {
+		if limits.memory, err = strconv.ParseInt(memoryLimit[0], 10, 64); err != nil {
+			return limits, err
+		}
+		if limits.memory >= cgroupUnlimited {
+			limits.memory = 0
+		}
+	}
+	return limits, nil
+}
+
+func cpuQuota(quota, period string) (float64, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, err
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil {
+		return 0, err
+	}
+	if q <= 0 || p <= 0 {
+		return 0, nil
+	}
+	return q / p, nil
+}
+
+// readCgroupFile returns the whitespace separated fields of a cgroup file.
+// A missing file is not an error since not every controller is always available.
+func readCgroupFile(path string) ([]string, bool, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return strings.Fields(string(b)), true, nil
+}
+
+var registerRuntimeMetricsOnce sync.Once
+
+// registerRuntimeMetrics replaces the default go collector by one exporting
+// all the go runtime/metrics.
+func registerRuntimeMetrics(logger *zerolog.Logger) {
+	registerRuntimeMetricsOnce.Do(func() {
+		prometheus.Unregister(collectors.NewGoCollector())
+		err := prometheus.Register(collectors.NewGoCollector(
+			collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll),
+		))
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to register the go runtime metrics.")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return root
+}
+
+func TestReadCgroupLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected cgroupLimits
+	}{
+		{
+			name: "v2",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "150000 100000\n",
+				"memory.max":         "536870912\n",
+			},
+			expected: cgroupLimits{cpu: 1.5, memory: 536870912},
+		},
+		{
+			name: "v2 unlimited",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "max 100000\n",
+				"memory.max":         "max\n",
+			},
+		},
+		{
+			name: "v1",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "50000\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "1073741824\n",
+			},
+			expected: cgroupLimits{cpu: 0.5, memory: 1073741824},
+		},
+		{
+			name: "v1 unlimited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+		},
+		{
+			name: "no cgroup",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := readCgroupLimits(writeCgroupFiles(t, tt.files))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, limits)
+		})
+	}
+
+	_, err := readCgroupLimits(writeCgroupFiles(t, map[string]string{
+		"cgroup.controllers": "memory",
+		"memory.max":         "lots",
+	}))
+	assert.ErrorContains(t, err, "app.readCgroupLimits: ")
+}
+
+func TestRuntimeLimits(t *testing.T) {
+	assert.Equal(t, int64(900), memoryLimitForCgroup(1000, 0.9))
+	assert.Equal(t, int64(1000), memoryLimitForCgroup(1000, 0), "invalid ratios must use the whole limit")
+}
+
+func TestTuneRuntime(t *testing.T) {
+	oldRoot := cgroupRoot
+	oldProcs := runtime.GOMAXPROCS(0)
+	oldMemLimit := debug.SetMemoryLimit(-1)
+	t.Cleanup(func() {
+		cgroupRoot = oldRoot
+		runtime.GOMAXPROCS(oldProcs)
+		debug.SetMemoryLimit(oldMemLimit)
+	})
+	t.Setenv("GOMAXPROCS", "")
+	t.Setenv("GOMEMLIMIT", "")
+
+	cgroupRoot = writeCgroupFiles(t, map[string]string{
+		"cgroup.controllers": "cpu memory",
+		"cpu.max":            "300000 100000",
+		"memory.max":         "1000000000",
+	})
+
+	logger := zerolog.Nop()
+	config := NewDefaultRuntimeConfig()
+	config.Tune = true
+	config.Metrics = true
+	tuneRuntime(&logger, config)
+
+	assert.Equal(t, oldProcs, runtime.GOMAXPROCS(0), "GOMAXPROCS must be left to the runtime")
+	assert.Equal(t, int64(900000000), debug.SetMemoryLimit(-1))
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	var found bool
+	for _, f := range families {
+		// Only exported when all the runtime/metrics are enabled
+		if strings.HasPrefix(f.GetName(), "go_sched_latencies_seconds") {
+			found = true
+		}
+	}
+	assert.True(t, found, "the go runtime/metrics must be registered")
+}
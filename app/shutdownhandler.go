@@ -55,6 +55,11 @@ type ShutdownHandler struct {
 	Handler ShutdownFunc
 	Policy  ErrorPolicy
 
+	// DependsOn lists the names of the handlers, with the same priority,
+	// that must finish before this handler starts. Handlers with a higher
+	// priority always finish before.
+	DependsOn []string
+
 	err   error
 	index int
 	order int
@@ -63,6 +68,10 @@ type ShutdownHandler struct {
 	Priority ShutdownPriority
 
 	executed bool
+
+	resultMu  sync.Mutex
+	result    ShutdownHandlerReport
+	startedAt time.Time
 }
 
 // Execute runs the shutdown functions and handles timeout and error policy
@@ -82,9 +91,13 @@ func (sh *ShutdownHandler) Execute(ctx context.Context) error {
 	// Avoid running if the context is already closed
 	if ctx.Err() != nil {
 		sh.err = errors.E(op, errors.E(errors.Op(sh.Name), "skipping handler as deadline has been reached"))
+		sh.setResult(ShutdownOutcomeSkipped, 0, sh.err)
 		return sh.err
 	}
 
+	start := time.Now()
+	sh.setResult(ShutdownOutcomeRunning, 0, nil)
+
 	// Set the configured timeout, if any
 	if sh.Timeout > 0 {
 		var cancel func()
@@ -96,6 +109,7 @@ func (sh *ShutdownHandler) Execute(ctx context.Context) error {
 	err := sh.Handler(ctx)
 	if err != nil {
 		err = errors.E(op, errors.E(errors.Op(sh.Name), err))
+		sh.setResult(ShutdownOutcomeFailed, time.Since(start), err)
 		switch sh.Policy {
 		case ErrorPolicyWarn:
 			log.Ctx(ctx).Warn().
@@ -117,6 +131,8 @@ func (sh *ShutdownHandler) Execute(ctx context.Context) error {
 		default:
 			panic(errors.Errorf("invalid error policy: %v", sh.Policy))
 		}
+	} else {
+		sh.setResult(ShutdownOutcomeSuccess, time.Since(start), nil)
 	}
 
 	log.Ctx(ctx).Info().
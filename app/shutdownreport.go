@@ -0,0 +1,148 @@
+package app
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+)
+
+// ShutdownOutcome is the result of a shutdown handler
+type ShutdownOutcome string
+
+const (
+	// ShutdownOutcomeNotStarted means the handler was never started, because
+	// the shutdown was aborted or its deadline was reached before.
+	ShutdownOutcomeNotStarted = ShutdownOutcome("not_started")
+	// ShutdownOutcomeSkipped means the handler was skipped because the
+	// shutdown deadline was already reached when it was its turn.
+	ShutdownOutcomeSkipped = ShutdownOutcome("skipped")
+	// ShutdownOutcomeRunning means the handler was still running when the
+	// shutdown deadline was reached.
+	ShutdownOutcomeRunning = ShutdownOutcome("running")
+	// ShutdownOutcomeSuccess means the handler finished without error.
+	ShutdownOutcomeSuccess = ShutdownOutcome("success")
+	// ShutdownOutcomeFailed means the handler returned an error.
+	ShutdownOutcomeFailed = ShutdownOutcome("failed")
+)
+
+// ShutdownHandlerReport describes the execution of a shutdown handler.
+type ShutdownHandlerReport struct {
+	Name     string
+	Priority ShutdownPriority
+	Policy   ErrorPolicy
+	Outcome  ShutdownOutcome
+	Duration time.Duration
+	Err      error
+}
+
+// MarshalZerologObject allows for zerolog to log the report as an object.
+func (r ShutdownHandlerReport) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("name", r.Name).
+		Uint8("priority", uint8(r.Priority)).
+		Str("policy", ErrorPolicyString(r.Policy)).
+		Str("outcome", string(r.Outcome)).
+		Dur("duration", r.Duration)
+	if r.Err != nil {
+		e.Str("error", r.Err.Error())
+	}
+}
+
+// ShutdownReport describes the execution of a graceful shutdown.
+type ShutdownReport struct {
+	// Duration is how long the shutdown took.
+	Duration time.Duration
+	// Handlers are sorted in the order they were scheduled.
+	Handlers []ShutdownHandlerReport
+	// Err is the error returned by the shutdown.
+	Err error
+	// LeakedGoroutines are the goroutines still alive after the shutdown, if
+	// the leak check is enabled.
+	LeakedGoroutines []LeakedGoroutine
+}
+
+// MarshalZerologObject allows for zerolog to log the report as an object.
+func (r ShutdownReport) MarshalZerologObject(e *zerolog.Event) {
+	e.Dur("duration", r.Duration)
+	if r.Err != nil {
+		e.Str("error", r.Err.Error())
+	}
+	arr := zerolog.Arr()
+	for _, h := range r.Handlers {
+		arr.Object(h)
+	}
+	e.Array("handlers", arr)
+	if len(r.LeakedGoroutines) > 0 {
+		e.Int("leaked_goroutines", len(r.LeakedGoroutines))
+	}
+}
+
+var (
+	shutdownDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "fkit",
+		Subsystem: "app",
+		Name:      "shutdown_duration_seconds",
+		Help:      "Duration of the last graceful shutdown in seconds.",
+	})
+	shutdownHandlerDuration = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "fkit",
+		Subsystem: "app",
+		Name:      "shutdown_handler_duration_seconds",
+		Help:      "Duration of the last execution of a shutdown handler in seconds.",
+	}, []string{"handler"})
+	shutdownHandlerOutcomes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fkit",
+		Subsystem: "app",
+		Name:      "shutdown_handler_outcomes_total",
+		Help:      "Total amount of shutdown handler executions by outcome.",
+	}, []string{"handler", "policy", "outcome"})
+)
+
+func (sh *ShutdownHandler) setResult(outcome ShutdownOutcome, duration time.Duration, err error) {
+	sh.resultMu.Lock()
+	defer sh.resultMu.Unlock()
+	if outcome == ShutdownOutcomeRunning {
+		sh.startedAt = time.Now()
+	}
+	sh.result.Outcome = outcome
+	sh.result.Duration = duration
+	sh.result.Err = err
+}
+
+// report returns the report of the handler. It's safe to call while the handler is running.
+func (sh *ShutdownHandler) report() ShutdownHandlerReport {
+	sh.resultMu.Lock()
+	defer sh.resultMu.Unlock()
+	r := sh.result
+	r.Name = sh.Name
+	r.Priority = sh.Priority
+	r.Policy = sh.Policy
+	switch r.Outcome {
+	case "":
+		r.Outcome = ShutdownOutcomeNotStarted
+	case ShutdownOutcomeRunning:
+		r.Duration = time.Since(sh.startedAt)
+	}
+	return r
+}
+
+func newShutdownReport(handlers []*ShutdownHandler, duration time.Duration, err error) ShutdownReport {
+	report := ShutdownReport{
+		Duration: duration,
+		Handlers: make([]ShutdownHandlerReport, len(handlers)),
+		Err:      err,
+	}
+	for i, h := range handlers {
+		report.Handlers[i] = h.report()
+	}
+	return report
+}
+
+func (r ShutdownReport) observe() {
+	shutdownDuration.Set(r.Duration.Seconds())
+	for _, h := range r.Handlers {
+		shutdownHandlerDuration.WithLabelValues(h.Name).Set(h.Duration.Seconds())
+		shutdownHandlerOutcomes.WithLabelValues(h.Name, ErrorPolicyString(h.Policy), string(h.Outcome)).Inc()
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown_Report(t *testing.T) {
+	a := &App{}
+	assert.Nil(t, a.LastShutdownReport())
+
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:     "ok",
+		Priority: 30,
+		Handler:  func(context.Context) error { return nil },
+	})
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:     "warn",
+		Priority: 20,
+		Policy:   ErrorPolicyWarn,
+		Handler:  func(context.Context) error { return errors.New("my warning") },
+	})
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:     "abort",
+		Priority: 10,
+		Policy:   ErrorPolicyAbort,
+		Handler:  func(context.Context) error { return errors.New("my error") },
+	})
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:     "never",
+		Priority: 0,
+		Handler:  func(context.Context) error { return nil },
+	})
+
+	err := a.Shutdown(context.Background())
+	require.Error(t, err)
+
+	report := a.LastShutdownReport()
+	require.NotNil(t, report)
+	assert.Equal(t, err, report.Err)
+	require.Len(t, report.Handlers, 4)
+
+	assert.Equal(t, "ok", report.Handlers[0].Name)
+	assert.Equal(t, ShutdownOutcomeSuccess, report.Handlers[0].Outcome)
+
+	assert.Equal(t, "warn", report.Handlers[1].Name)
+	assert.Equal(t, ShutdownOutcomeFailed, report.Handlers[1].Outcome)
+	assert.EqualError(t, report.Handlers[1].Err, "app.shutdownHandler.Execute: warn: my warning")
+
+	assert.Equal(t, "abort", report.Handlers[2].Name)
+	assert.Equal(t, ShutdownOutcomeFailed, report.Handlers[2].Outcome)
+	assert.Equal(t, ErrorPolicyAbort, report.Handlers[2].Policy)
+
+	assert.Equal(t, "never", report.Handlers[3].Name)
+	assert.Equal(t, ShutdownOutcomeNotStarted, report.Handlers[3].Outcome)
+}
+
+func TestShutdown_ReportDeadline(t *testing.T) {
+	a := &App{ShutdownTimeout: 50 * time.Millisecond}
+
+	release := make(chan struct{})
+	defer close(release)
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:     "slow",
+		Priority: 10,
+		Handler: func(context.Context) error {
+			<-release
+			return nil
+		},
+	})
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:    "late",
+		Handler: func(context.Context) error { return nil },
+	})
+
+	require.EqualError(t, a.Shutdown(context.Background()), "app.App.Shutdown: shutdown deadline has been reached")
+
+	report := a.LastShutdownReport()
+	require.Len(t, report.Handlers, 2)
+	assert.Equal(t, ShutdownOutcomeRunning, report.Handlers[0].Outcome)
+	assert.GreaterOrEqual(t, report.Handlers[0].Duration, 50*time.Millisecond)
+	assert.Equal(t, ShutdownOutcomeNotStarted, report.Handlers[1].Outcome)
+}
+
+func TestShutdownHandlerExecute_ReportSkipped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sh := &ShutdownHandler{
+		Name:    testHandlerName,
+		Handler: func(context.Context) error { return nil },
+	}
+	assert.Error(t, sh.Execute(ctx))
+	assert.Equal(t, ShutdownOutcomeSkipped, sh.report().Outcome)
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// shutdownTiers splits the handlers, already sorted from the highest to the
+// lowest priority, in groups of the same priority.
+func shutdownTiers(handlers []*ShutdownHandler) [][]*ShutdownHandler {
+	var tiers [][]*ShutdownHandler
+	for i, h := range handlers {
+		if i == 0 || handlers[i-1].Priority != h.Priority {
+			tiers = append(tiers, nil)
+		}
+		tiers[len(tiers)-1] = append(tiers[len(tiers)-1], h)
+	}
+	return tiers
+}
+
+// shutdownNode is a handler in the dependency graph of a tier.
+type shutdownNode struct {
+	handler    *ShutdownHandler
+	pending    int
+	dependents []*shutdownNode
+}
+
+// newShutdownGraph builds the dependency graph of the handlers of a tier.
+// Dependencies on handlers of a higher priority are already satisfied and are
+// ignored. Unknown dependencies, dependencies on handlers of a lower priority
+// and dependency cycles are logged and ignored so the handlers still run.
+func newShutdownGraph(tier []*ShutdownHandler, executed map[string]bool) []*shutdownNode {
+	nodes := make([]*shutdownNode, len(tier))
+	byName := make(map[string][]*shutdownNode, len(tier))
+	for i, h := range tier {
+		nodes[i] = &shutdownNode{handler: h}
+		byName[h.Name] = append(byName[h.Name], nodes[i])
+	}
+
+	for _, n := range nodes {
+		for _, dep := range n.handler.DependsOn {
+			deps, ok := byName[dep]
+			if !ok {
+				if !executed[dep] {
+					log.Warn().
+						Str("shutdown_handler_name", n.handler.Name).
+						Str("shutdown_handler_dependency", dep).
+						Msg("[app] Ignoring unknown or lower priority shutdown handler dependency.")
+				}
+				continue
+			}
+			for _, d := range deps {
+				if d == n {
+					continue
+				}
+				d.dependents = append(d.dependents, n)
+				n.pending++
+			}
+		}
+	}
+
+	breakShutdownCycles(nodes)
+
+	return nodes
+}
+
+// breakShutdownCycles drops the dependencies between handlers that are part
+// of the same dependency cycle. Dependencies on and from handlers outside the
+// cycle are kept.
+func breakShutdownCycles(nodes []*shutdownNode) {
+	for _, component := range shutdownCycles(nodes) {
+		inCycle := make(map[*shutdownNode]bool, len(component))
+		for _, n := range component {
+			inCycle[n] = true
+		}
+		for _, n := range component {
+			log.Error().
+				Str("shutdown_handler_name", n.handler.Name).
+				Strs("shutdown_handler_depends_on", n.handler.DependsOn).
+				Msg("[app] Shutdown handler dependency cycle detected, ignoring dependencies inside the cycle.")
+
+			dependents := n.dependents[:0]
+			for _, d := range n.dependents {
+				if inCycle[d] {
+					d.pending--
+					continue
+				}
+				dependents = append(dependents, d)
+			}
+			n.dependents = dependents
+		}
+	}
+}
+
+// shutdownCycles returns the strongly connected components with more than one
+// node, using Tarjan's algorithm.
+func shutdownCycles(nodes []*shutdownNode) [][]*shutdownNode {
+	index := make(map[*shutdownNode]int, len(nodes))
+	lowlink := make(map[*shutdownNode]int, len(nodes))
+	onStack := make(map[*shutdownNode]bool, len(nodes))
+	var stack []*shutdownNode
+	var cycles [][]*shutdownNode
+
+	var visit func(n *shutdownNode)
+	visit = func(n *shutdownNode) {
+		index[n] = len(index)
+		lowlink[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, d := range n.dependents {
+			if _, visited := index[d]; !visited {
+				visit(d)
+				lowlink[n] = min(lowlink[n], lowlink[d])
+			} else if onStack[d] {
+				lowlink[n] = min(lowlink[n], index[d])
+			}
+		}
+
+		if lowlink[n] != index[n] {
+			return
+		}
+		var component []*shutdownNode
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == n {
+				break
+			}
+		}
+		if len(component) > 1 {
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, visited := index[n]; !visited {
+			visit(n)
+		}
+	}
+	return cycles
+}
+
+type shutdownResult struct {
+	node *shutdownNode
+	err  error
+}
+
+// runShutdownTier executes the handlers of a tier. A handler starts as soon as
+// all its dependencies finished, so independent handlers run in parallel.
+// If a handler fails with ErrorPolicyAbort, no other handler is started and
+// the error is returned after the running ones finish.
+func runShutdownTier(ctx context.Context, tier []*ShutdownHandler, executed map[string]bool) error {
+	const op = errors.Op("runShutdownTier")
+
+	nodes := newShutdownGraph(tier, executed)
+	results := make(chan shutdownResult, len(nodes))
+
+	running := 0
+	start := func(n *shutdownNode) {
+		running++
+		go func() {
+			results <- shutdownResult{node: n, err: executeShutdownHandler(ctx, n.handler)}
+		}()
+	}
+
+	for _, n := range nodes {
+		if n.pending == 0 {
+			start(n)
+		}
+	}
+
+	var err error
+	for running > 0 {
+		r := <-results
+		running--
+		executed[r.node.handler.Name] = true
+
+		if r.err != nil {
+			if err == nil {
+				err = errors.E(op, r.err)
+			}
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		for _, d := range r.node.dependents {
+			d.pending--
+			if d.pending == 0 {
+				start(d)
+			}
+		}
+	}
+
+	return err
+}
+
+func executeShutdownHandler(ctx context.Context, h *ShutdownHandler) error {
+	logger := log.With().
+		Str("shutdown_handler_name", h.Name).
+		Uint8("shutdown_handler_priority", uint8(h.Priority)).
+		Dur("shutdown_handler_timeout", h.Timeout).
+		Str("shutdown_handler_policy", ErrorPolicyString(h.Policy)).
+		Strs("shutdown_handler_depends_on", h.DependsOn).
+		Logger()
+
+	logger.Trace().Msg("[app] Executing shutdown handler.")
+	if err := h.Execute(ctx); err != nil {
+		logger.Trace().Msg("[app] Shutdown handler failed.")
+		return err
+	}
+	logger.Trace().Msg("[app] Shutdown handler finished.")
+	return nil
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shutdownRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *shutdownRecorder) handler(name string, priority ShutdownPriority, dependsOn ...string) *ShutdownHandler {
+	return &ShutdownHandler{
+		Name:      name,
+		Priority:  priority,
+		DependsOn: dependsOn,
+		Policy:    ErrorPolicyAbort,
+		Handler: func(context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.order = append(r.order, name)
+			return nil
+		},
+	}
+}
+
+func (r *shutdownRecorder) indexOf(name string) int {
+	for i, n := range r.order {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestShutdown_DependsOn(t *testing.T) {
+	a := &App{}
+	r := &shutdownRecorder{}
+
+	a.RegisterShutdownHandler(r.handler("database", 10, "producer"))
+	a.RegisterShutdownHandler(r.handler("producer", 10, "http_server"))
+	a.RegisterShutdownHandler(r.handler("http_server", 10))
+	a.RegisterShutdownHandler(r.handler("cache", 10))
+	a.RegisterShutdownHandler(r.handler("tracer", 5, "database", "unknown"))
+	a.RegisterShutdownHandler(r.handler("metrics", 20))
+
+	require.NoError(t, a.Shutdown(context.Background()))
+
+	require.Len(t, r.order, 6)
+	assert.Equal(t, "metrics", r.order[0], "highest priority runs first")
+	assert.Equal(t, "tracer", r.order[5], "lowest priority runs last")
+	assert.Less(t, r.indexOf("http_server"), r.indexOf("producer"))
+	assert.Less(t, r.indexOf("producer"), r.indexOf("database"))
+}
+
+func TestShutdown_Parallel(t *testing.T) {
+	a := &App{}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for _, name := range []string{"a", "b"} {
+		a.RegisterShutdownHandler(&ShutdownHandler{
+			Name: name,
+			Handler: func(ctx context.Context) error {
+				// Each handler waits for the other, so they must run in parallel
+				wg.Done()
+				wg.Wait()
+				return nil
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, a.Shutdown(ctx))
+}
+
+func TestShutdown_Cycle(t *testing.T) {
+	a := &App{}
+	r := &shutdownRecorder{}
+
+	a.RegisterShutdownHandler(r.handler("a", 0, "b"))
+	a.RegisterShutdownHandler(r.handler("b", 0, "a"))
+	a.RegisterShutdownHandler(r.handler("c", 0, "b"))
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, r.order)
+	assert.Less(t, r.indexOf("b"), r.indexOf("c"))
+}
+
+func TestShutdown_Abort(t *testing.T) {
+	a := &App{}
+	r := &shutdownRecorder{}
+
+	a.RegisterShutdownHandler(&ShutdownHandler{
+		Name:     "failing",
+		Priority: 10,
+		Policy:   ErrorPolicyAbort,
+		Handler: func(context.Context) error {
+			return errors.New("my error")
+		},
+	})
+	a.RegisterShutdownHandler(r.handler("dependent", 10, "failing"))
+	a.RegisterShutdownHandler(r.handler("lower", 5))
+
+	err := a.Shutdown(context.Background())
+	assert.EqualError(t, err, "app.App.Shutdown: shutdownAllHandlers: runShutdownTier: app.shutdownHandler.Execute: failing: my error")
+	assert.Empty(t, r.order, "no handler should run after an abort")
+}
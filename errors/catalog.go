@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+)
+
+// CodeInfo describes an error code registered in the catalog.
+type CodeInfo struct {
+	Code Code `json:"code"`
+	// Severity is the severity returned by GetSeverity for errors with this
+	// code and without a severity.
+	Severity Severity `json:"severity,omitempty"`
+	// HTTPStatus is the status of API responses failing with this code.
+	HTTPStatus int `json:"http_status,omitempty"`
+	// Retryable tells if an operation failing with this code may succeed
+	// if executed again.
+	Retryable bool `json:"retryable"`
+	// Description explains what the code means to the API consumers.
+	Description string `json:"description,omitempty"`
+	// Owner is who is responsible for the code, like a team or a package.
+	Owner string `json:"owner,omitempty"`
+}
+
+var catalog = struct {
+	mu    sync.RWMutex
+	codes map[Code]CodeInfo
+}{
+	codes: map[Code]CodeInfo{},
+}
+
+// RegisterCode adds the code to the catalog and returns it, so it can be used
+// to declare the code:
+//
+//	var ErrCodeInvalidItem = errors.RegisterCode(errors.CodeInfo{
+//		Code:        "INVALID_ITEM",
+//		Severity:    errors.SeverityInput,
+//		HTTPStatus:  http.StatusBadRequest,
+//		Description: "The item is not valid.",
+//		Owner:       "team-items",
+//	})
+//
+// Different owners may use the same code, like a library and a service both
+// using "TIMEOUT". In that case the first registration is kept, so importing a
+// package never changes the info of a code registered before. Libraries
+// should leave Severity and HTTPStatus unset and set the severity when
+// creating their errors, so the catalog doesn't change errors they didn't
+// create.
+//
+// It panics if the code is empty or if the same owner registers the code
+// again with a different info.
+func RegisterCode(info CodeInfo) Code {
+	if info.Code == CodeEmpty {
+		panic("error code must not be empty")
+	}
+
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+	registered, ok := catalog.codes[info.Code]
+	if !ok {
+		catalog.codes[info.Code] = info
+		return info.Code
+	}
+	if registered.Owner == info.Owner && registered != info {
+		panic("error code " + string(info.Code) + " already registered by " + info.Owner)
+	}
+	return info.Code
+}
+
+// LookupCode returns the info of a code registered in the catalog.
+func LookupCode(code Code) (CodeInfo, bool) {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+	info, ok := catalog.codes[code]
+	return info, ok
+}
+
+// Codes returns the info of all the codes registered in the catalog, sorted by code.
+func Codes() []CodeInfo {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+	codes := make([]CodeInfo, 0, len(catalog.codes))
+	for _, info := range catalog.codes {
+		codes = append(codes, info)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterCode(t *testing.T) {
+	info := CodeInfo{
+		Code:        "CATALOG_TEST_CODE",
+		Severity:    SeverityInput,
+		HTTPStatus:  400,
+		Description: "Test code.",
+		Owner:       "errors",
+	}
+	code := RegisterCode(info)
+	assert.Equal(t, Code("CATALOG_TEST_CODE"), code)
+
+	registered, ok := LookupCode(code)
+	assert.True(t, ok)
+	assert.Equal(t, info, registered)
+	assert.Contains(t, Codes(), info)
+
+	_, ok = LookupCode("CATALOG_TEST_UNKNOWN")
+	assert.False(t, ok)
+
+	assert.NotPanics(t, func() { RegisterCode(info) }, "registering the same info again is allowed")
+	assert.Equal(t, code, RegisterCode(CodeInfo{Code: code, Severity: SeverityFatal, Owner: "service"}))
+	registered, _ = LookupCode(code)
+	assert.Equal(t, info, registered, "the first registration is kept")
+	assert.PanicsWithValue(t, "error code CATALOG_TEST_CODE already registered by errors", func() {
+		RegisterCode(CodeInfo{Code: code, Severity: SeverityFatal, Owner: "errors"})
+	})
+
+	assert.PanicsWithValue(t, "error code must not be empty", func() { RegisterCode(CodeInfo{}) })
+}
+
+func TestCodes_Sorted(t *testing.T) {
+	RegisterCode(CodeInfo{Code: "CATALOG_TEST_B"})
+	RegisterCode(CodeInfo{Code: "CATALOG_TEST_A"})
+
+	codes := Codes()
+	for i := 1; i < len(codes); i++ {
+		assert.Less(t, codes[i-1].Code, codes[i].Code)
+	}
+}
+
+func TestGetSeverity_Catalog(t *testing.T) {
+	code := RegisterCode(CodeInfo{Code: "CATALOG_TEST_SEVERITY", Severity: SeverityInput})
+
+	assert.Equal(t, SeverityInput, GetSeverity(E(code, "my error")))
+	assert.Equal(t, SeverityRuntime, GetSeverity(E(code, SeverityRuntime, "my error")), "explicit severity wins")
+	assert.Equal(t, SeverityUnset, GetSeverity(E(Code("CATALOG_TEST_UNKNOWN"), "my error")))
+
+	assert.Equal(t, SeverityUnset, GetExplicitSeverity(E(code, "my error")))
+	assert.Equal(t, SeverityRuntime, GetExplicitSeverity(E(code, SeverityRuntime, "my error")))
+}
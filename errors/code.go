@@ -26,19 +26,21 @@ const (
 )
 
 // GetCode returns the error code. If the error doesn't contains
-// an error code, returns ErrorCodeEmpty
+// an error code, returns ErrorCodeEmpty. If the error aggregates many errors,
+// like Multi, the code of the child with the dominant severity is returned.
 func GetCode(err error) Code {
-	for {
-		var e Error
-
-		ok := errors.As(err, &e)
-		if !ok {
-			break
+	for err != nil {
+		if e, ok := err.(Error); ok {
+			if e.Code != CodeEmpty {
+				return e.Code
+			}
+			err = e.Err
+			continue
 		}
-		if e.Code != CodeEmpty {
-			return e.Code
+		if errs := unwrapMulti(err); errs != nil {
+			return multiCode(errs)
 		}
-		err = e.Err
+		err = errors.Unwrap(err)
 	}
 
 	return CodeEmpty
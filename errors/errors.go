@@ -26,6 +26,9 @@ type Error struct {
 	// KVs is a map os values you can use to enrich your error with relevant
 	// information
 	KVs []KeyValue
+
+	// stack is the stack of the call to E, if captured.
+	stack *Stack
 }
 
 // Error formats the error information into a string. By implementing this
@@ -102,10 +105,14 @@ func (e Error) Unwrap() error {
 // only the last value of each type will be considered, except for KeyValue and
 // []KeyValue, which will be concatenated to the struct's KVs value.
 //
-// Types other than string, Code, Severity, error, Op, KeyValue, or []KeyValue
-// will simply be ignored.
+// If stack capture is enabled with SetStackCapture or a StackCapture is given
+// as WithStack, the stack is captured, unless the error already has one.
+//
+// Types other than string, Code, Severity, error, Op, KeyValue, []KeyValue or
+// StackCapture will simply be ignored.
 func E(args ...interface{}) error {
 	e := Error{}
+	withStack := captureStack.Load()
 	if len(args) == 0 {
 		msg := "errors.E called with 0 args"
 		_, file, line, ok := runtime.Caller(1)
@@ -131,6 +138,8 @@ func E(args ...interface{}) error {
 			e.KVs = append(e.KVs, a)
 		case []KeyValue:
 			e.KVs = append(e.KVs, a...)
+		case StackCapture:
+			withStack = bool(a)
 		}
 	}
 
@@ -139,6 +148,10 @@ func E(args ...interface{}) error {
 		return nil
 	}
 
+	if withStack && GetStack(e.Err) == nil {
+		e.stack = callers(1)
+	}
+
 	return e
 }
 
@@ -187,7 +200,8 @@ func ConcatErrorsMessage(errs ...error) string {
 }
 
 // ConcatErrors returns an error with a message that is the concatenation of all
-// messages of the given errors
+// messages of the given errors. The codes and severities of the errors are
+// lost, use Join to keep them.
 func ConcatErrors(errs ...error) error {
 	return New(ConcatErrorsMessage(errs...))
 }
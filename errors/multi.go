@@ -0,0 +1,150 @@
+package errors
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Multi is an error aggregating many errors, like the validation errors of
+// the items of a batch. Each child keeps its own Code and Severity.
+//
+// GetCode and GetSeverity walk all the children, using DominantSeverity to
+// pick the severity of the aggregate. Go's errors.Is and errors.As also walk
+// all the children.
+type Multi struct {
+	Errs []error
+}
+
+// Join returns a Multi with the given errors, ignoring the nil ones. If all
+// the errors are nil, Join returns nil, like Go's errors.Join.
+func Join(errs ...error) error {
+	m := &Multi{}
+	for _, err := range errs {
+		if err != nil {
+			m.Errs = append(m.Errs, err)
+		}
+	}
+	if len(m.Errs) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error formats the children in a single line, like "2 errors: a; b".
+func (m *Multi) Error() string {
+	if len(m.Errs) == 1 {
+		return m.Errs[0].Error()
+	}
+	s := strings.Builder{}
+	s.WriteString(strconv.Itoa(len(m.Errs)))
+	s.WriteString(" errors: ")
+	for i, err := range m.Errs {
+		if i > 0 {
+			s.WriteString("; ")
+		}
+		s.WriteString(err.Error())
+	}
+	return s.String()
+}
+
+// String is required to implement the stringer interface
+func (m *Multi) String() string {
+	return m.Error()
+}
+
+// Unwrap returns the children, so Go's errors.Is and errors.As walk all of them.
+func (m *Multi) Unwrap() []error {
+	return m.Errs
+}
+
+// MarshalZerologArray allows for zerolog to log the children as an array of
+// objects with their message, code and severity.
+func (m *Multi) MarshalZerologArray(a *zerolog.Array) {
+	errorsArray(m.Errs).MarshalZerologArray(a)
+}
+
+// errorsArray logs errors as an array of objects with their message, code and severity.
+type errorsArray []error
+
+func (errs errorsArray) MarshalZerologArray(a *zerolog.Array) {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		a.Dict(zerolog.Dict().
+			Str("error", err.Error()).
+			EmbedObject(GetCode(err)).
+			EmbedObject(GetSeverity(err)))
+	}
+}
+
+// DominantSeverity picks the severity of an aggregate of errors, like Multi,
+// from the severities of its children. It defaults to MostSevere and may be
+// replaced at the start of the program.
+var DominantSeverity = MostSevere
+
+// MostSevere returns the most severe of the severities, in the order fatal,
+// runtime, input and unset. Unknown severities rank right after fatal.
+func MostSevere(severities ...Severity) Severity {
+	dominant := SeverityUnset
+	for _, s := range severities {
+		if severityRank(s) > severityRank(dominant) {
+			dominant = s
+		}
+	}
+	return dominant
+}
+
+func severityRank(s Severity) int {
+	switch s {
+	case SeverityUnset:
+		return 0
+	case SeverityInput:
+		return 1
+	case SeverityRuntime:
+		return 2
+	case SeverityFatal:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// unwrapMulti returns the children of err if it aggregates many errors, like
+// Multi or the errors returned by Go's errors.Join.
+func unwrapMulti(err error) []error {
+	if m, ok := err.(interface{ Unwrap() []error }); ok {
+		return m.Unwrap()
+	}
+	return nil
+}
+
+// multiSeverity returns the dominant severity of the children, as returned
+// by getChild.
+func multiSeverity(errs []error, getChild func(error) Severity) Severity {
+	severities := make([]Severity, 0, len(errs))
+	for _, err := range errs {
+		severities = append(severities, getChild(err))
+	}
+	return DominantSeverity(severities...)
+}
+
+// multiCode returns the code of the first child with the dominant severity,
+// so the code and the severity of the aggregate match. If none of them has a
+// code, the first code of the children is returned.
+func multiCode(errs []error) Code {
+	dominant := multiSeverity(errs, GetSeverity)
+	for _, err := range errs {
+		if code := GetCode(err); code != CodeEmpty && GetSeverity(err) == dominant {
+			return code
+		}
+	}
+	for _, err := range errs {
+		if code := GetCode(err); code != CodeEmpty {
+			return code
+		}
+	}
+	return CodeEmpty
+}
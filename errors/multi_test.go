@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin(t *testing.T) {
+	assert.Nil(t, Join())
+	assert.Nil(t, Join(nil, nil))
+
+	single := New("single")
+	assert.EqualError(t, Join(nil, single), "single")
+
+	err := E(Op("batch"), Join(
+		E(Op("item"), "invalid name", KV("item", 1)),
+		nil,
+		io.EOF,
+	))
+	assert.EqualError(t, err, "batch: 2 errors: item: invalid name [item=1]; EOF")
+	assert.True(t, errors.Is(err, io.EOF))
+
+	var multi *Multi
+	require.True(t, errors.As(err, &multi))
+	assert.Len(t, multi.Errs, 2)
+}
+
+func TestMulti_CodeAndSeverity(t *testing.T) {
+	inputErr := E("invalid item", SeverityInput, Code("INVALID_ITEM"))
+	fatalErr := E("database is down", SeverityFatal, Code("DATABASE_DOWN"))
+	runtimeErr := E("timeout", SeverityRuntime)
+
+	err := Join(inputErr, runtimeErr, fatalErr)
+	assert.Equal(t, SeverityFatal, GetSeverity(err))
+	assert.Equal(t, Code("DATABASE_DOWN"), GetCode(err))
+
+	// The runtime error has no code, so the first code is used
+	err = E(Op("batch"), Join(inputErr, runtimeErr))
+	assert.Equal(t, SeverityRuntime, GetSeverity(err))
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(err))
+
+	// The outer error takes precedence
+	err = E(Join(inputErr, fatalErr), SeverityInput, Code("BATCH_FAILED"))
+	assert.Equal(t, SeverityInput, GetSeverity(err))
+	assert.Equal(t, Code("BATCH_FAILED"), GetCode(err))
+
+	// Go's errors.Join is also walked
+	err = errors.Join(New("no severity"), inputErr)
+	assert.Equal(t, SeverityInput, GetSeverity(err))
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(err))
+
+	assert.Equal(t, SeverityUnset, GetSeverity(Join(New("a"), New("b"))))
+	assert.Equal(t, CodeEmpty, GetCode(Join(New("a"), New("b"))))
+}
+
+func TestMulti_DominantSeverity(t *testing.T) {
+	defer func(rule func(...Severity) Severity) { DominantSeverity = rule }(DominantSeverity)
+	DominantSeverity = func(severities ...Severity) Severity {
+		for _, s := range severities {
+			if s == SeverityInput {
+				return s
+			}
+		}
+		return MostSevere(severities...)
+	}
+
+	err := Join(
+		E("database is down", SeverityFatal, Code("DATABASE_DOWN")),
+		E("invalid item", SeverityInput, Code("INVALID_ITEM")),
+	)
+	assert.Equal(t, SeverityInput, GetSeverity(err))
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(err))
+}
+
+func TestMostSevere(t *testing.T) {
+	assert.Equal(t, SeverityUnset, MostSevere())
+	assert.Equal(t, SeverityInput, MostSevere(SeverityUnset, SeverityInput))
+	assert.Equal(t, SeverityRuntime, MostSevere(SeverityInput, SeverityRuntime, SeverityUnset))
+	assert.Equal(t, SeverityFatal, MostSevere(SeverityRuntime, SeverityFatal, Severity("custom")))
+	assert.Equal(t, Severity("custom"), MostSevere(SeverityRuntime, Severity("custom")))
+}
+
+func TestMulti_MarshalZerologArray(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := zerolog.New(&buf)
+	multi := &Multi{Errs: []error{E("invalid item", SeverityInput, Code("INVALID_ITEM")), New("plain")}}
+	logger.Log().Array("errors", multi).Send()
+
+	assert.JSONEq(t, `{"errors":[
+		{"error":"invalid item","error_code":"INVALID_ITEM","error_severity":"input"},
+		{"error":"plain","error_code":"","error_severity":""}
+	]}`, buf.String())
+}
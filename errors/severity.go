@@ -32,20 +32,43 @@ func (s Severity) MarshalZerologObject(e *zerolog.Event) {
 	e.Str("error_severity", string(s))
 }
 
-// GetSeverity returns the error severity. If there is not severity, SeverityUnset is returned.
+// GetSeverity returns the error severity. If there is not severity, the
+// default severity of its code in the catalog is returned or, if the code is
+// not registered, SeverityUnset is returned.
+// If the error aggregates many errors, like Multi, the DominantSeverity of the children is returned.
 func GetSeverity(err error) Severity {
-	for {
-		var e Error
+	if severity := getSeverity(err, GetSeverity); severity != SeverityUnset {
+		return severity
+	}
+
+	if info, ok := LookupCode(GetCode(err)); ok {
+		return info.Severity
+	}
+	return SeverityUnset
+}
+
+// GetExplicitSeverity returns the severity set in the error chain, ignoring
+// the default severity of its code in the catalog. It's useful to tell if the
+// catalog defaults apply to the error.
+func GetExplicitSeverity(err error) Severity {
+	return getSeverity(err, GetExplicitSeverity)
+}
 
-		ok := errors.As(err, &e)
-		if !ok {
-			break
+// getSeverity walks the chain looking for a severity, using getChild for the
+// children of errors that aggregate many errors.
+func getSeverity(err error, getChild func(error) Severity) Severity {
+	for err != nil {
+		if e, ok := err.(Error); ok {
+			if e.Severity != SeverityUnset {
+				return e.Severity
+			}
+			err = e.Err
+			continue
 		}
-		if e.Severity != SeverityUnset {
-			return e.Severity
+		if errs := unwrapMulti(err); errs != nil {
+			return multiSeverity(errs, getChild)
 		}
-		err = e.Err
+		err = errors.Unwrap(err)
 	}
-
 	return SeverityUnset
 }
@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// StackCapture tells E whether to capture the stack of the call. When given
+// to E, it overrides the global setting of SetStackCapture.
+type StackCapture bool
+
+const (
+	// WithStack makes E capture the stack, unless the wrapped error already has one.
+	WithStack = StackCapture(true)
+	// WithoutStack makes E not capture the stack.
+	WithoutStack = StackCapture(false)
+)
+
+// maxStackDepth is how many frames are captured.
+const maxStackDepth = 32
+
+var captureStack atomic.Bool
+
+// SetStackCapture sets whether E captures the stack by default. Only the
+// program counters are captured, the frames are resolved when the stack is
+// formatted. The stack is captured only by the first E call of an error chain.
+func SetStackCapture(enabled bool) {
+	captureStack.Store(enabled)
+}
+
+// Stack is the stack of the call to E that created the error, as program counters.
+type Stack []uintptr
+
+// callers returns the stack of the caller, skipping skip frames above it.
+func callers(skip int) *Stack {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	stack := Stack(append([]uintptr(nil), pcs[:n]...))
+	return &stack
+}
+
+// Frames resolves the program counters into frames.
+func (s Stack) Frames() []runtime.Frame {
+	if len(s) == 0 {
+		return nil
+	}
+	frames := make([]runtime.Frame, 0, len(s))
+	iter := runtime.CallersFrames(s)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// String formats the stack like a panic stack trace.
+func (s Stack) String() string {
+	b := strings.Builder{}
+	for _, f := range s.Frames() {
+		b.WriteString(f.Function)
+		b.WriteString("\n\t")
+		b.WriteString(f.File)
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// MarshalZerologArray allows for zerolog to log the stack as an array of
+// frames, each one with its function, file and line.
+func (s Stack) MarshalZerologArray(a *zerolog.Array) {
+	for _, f := range s.Frames() {
+		a.Dict(zerolog.Dict().
+			Str("function", f.Function).
+			Str("file", f.File).
+			Int("line", f.Line))
+	}
+}
+
+// GetStack returns the stack captured by E. If the error has no stack, nil is returned.
+func GetStack(err error) Stack {
+	for {
+		var e Error
+
+		ok := errors.As(err, &e)
+		if !ok {
+			break
+		}
+		if e.stack != nil {
+			return *e.stack
+		}
+		err = e.Err
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStack_Disabled(t *testing.T) {
+	err := E(Op("op"), "my error")
+	assert.Nil(t, GetStack(err))
+	assert.Nil(t, GetStack(New("not an Error")))
+
+	err = E(Op("op"), "my error", WithStack)
+	assert.NotNil(t, GetStack(err))
+}
+
+func TestGetStack_Global(t *testing.T) {
+	SetStackCapture(true)
+	defer SetStackCapture(false)
+
+	err := newErrorWithStack()
+	stack := GetStack(err)
+	require.NotEmpty(t, stack)
+	frames := stack.Frames()
+	assert.Equal(t, "github.com/arquivei/foundationkit/errors.newErrorWithStack", frames[0].Function)
+	assert.Equal(t, "github.com/arquivei/foundationkit/errors.TestGetStack_Global", frames[1].Function)
+
+	// Only the first E call captures the stack
+	wrapped := E(Op("outer"), fmt.Errorf("wrapped: %w", err))
+	assert.Equal(t, stack, GetStack(wrapped))
+
+	assert.Nil(t, GetStack(E("my error", WithoutStack)))
+}
+
+func newErrorWithStack() error {
+	return E(Op("inner"), "my error")
+}
+
+func TestStack_Format(t *testing.T) {
+	stack := GetStack(newErrorWithStackArg())
+
+	assert.Regexp(t, `^github.com/arquivei/foundationkit/errors.newErrorWithStackArg\n\t.+/errors/stack_test.go:\d+\n`, stack.String())
+
+	buf := bytes.Buffer{}
+	logger := zerolog.New(&buf)
+	logger.Log().Array("error_stack", stack).Send()
+	var parsed struct {
+		Stack []struct {
+			Function string `json:"function"`
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+		} `json:"error_stack"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &parsed))
+	require.NotEmpty(t, parsed.Stack)
+	assert.Equal(t, "github.com/arquivei/foundationkit/errors.newErrorWithStackArg", parsed.Stack[0].Function)
+	assert.Contains(t, parsed.Stack[0].File, "stack_test.go")
+	assert.Positive(t, parsed.Stack[0].Line)
+}
+
+func newErrorWithStackArg() error {
+	return E("my error", WithStack)
+}
@@ -0,0 +1,200 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// WireAvroSchema is the avro schema of Wire, to send errors through avro
+// encoded messages.
+const WireAvroSchema = `{
+	"type": "record",
+	"name": "Error",
+	"namespace": "foundationkit.errors",
+	"fields": [
+		{"name": "op", "type": "string", "default": ""},
+		{"name": "code", "type": "string", "default": ""},
+		{"name": "severity", "type": "string", "default": ""},
+		{"name": "kvs", "type": {"type": "array", "items": {
+			"type": "record",
+			"name": "KeyValue",
+			"fields": [
+				{"name": "key", "type": "string"},
+				{"name": "value", "type": "string"}
+			]
+		}}, "default": []},
+		{"name": "message", "type": "string", "default": ""},
+		{"name": "err", "type": ["null", "Error"], "default": null},
+		{"name": "errs", "type": {"type": "array", "items": "Error"}, "default": []}
+	]
+}`
+
+// Wire is the serializable form of an error chain, to propagate errors across
+// services keeping the ops, codes, severities and KVs of each Error.
+//
+// Each Error of the chain is a Wire with the next one in Err. The last one
+// only has the Message of the root error. A Multi has its children in Errs.
+// Other errors wrapping errors, like the ones created by fmt.Errorf with %w or
+// Go's errors.Join, keep their Message and have the wrapped errors in Err or
+// Errs. KVs are sent as strings.
+//
+// The Wire exposes the internal ops and KVs of the chain to whoever receives
+// it, so only send it to trusted services and keep sensitive data out of KVs.
+type Wire struct {
+	Op       string   `json:"op,omitempty" avro:"op"`
+	Code     string   `json:"code,omitempty" avro:"code"`
+	Severity string   `json:"severity,omitempty" avro:"severity"`
+	KVs      []WireKV `json:"kvs,omitempty" avro:"kvs"`
+	Message  string   `json:"message,omitempty" avro:"message"`
+	Err      *Wire    `json:"err,omitempty" avro:"err"`
+	Errs     []Wire   `json:"errs,omitempty" avro:"errs"`
+}
+
+// WireErrorResponse is an HTTP error response carrying the whole error chain,
+// so clients like httpcomm can reconstruct it with its codes and severities.
+type WireErrorResponse struct {
+	Error *Wire `json:"error"`
+}
+
+const (
+	// WireErrorHeader is the HTTP header marking a response body as a
+	// WireErrorResponse, with WireErrorFormat as value. Clients only
+	// reconstruct the errors of responses with this header, so other bodies
+	// that look alike are left alone.
+	WireErrorHeader = "X-Error-Format"
+	// WireErrorFormat is the value of WireErrorHeader.
+	WireErrorFormat = "foundationkit-wire"
+)
+
+// WireKV is the serializable form of a KeyValue.
+type WireKV struct {
+	Key   string `json:"key" avro:"key"`
+	Value string `json:"value" avro:"value"`
+}
+
+// ToWire returns the serializable form of err. If err is nil, nil is returned.
+func ToWire(err error) *Wire {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case Error:
+		w := &Wire{
+			Op:       string(e.Op),
+			Code:     string(e.Code),
+			Severity: string(e.Severity),
+			Err:      ToWire(e.Err),
+		}
+		for _, kv := range e.KVs {
+			w.KVs = append(w.KVs, WireKV{Key: fmt.Sprint(kv.Key), Value: fmt.Sprint(kv.Value)})
+		}
+		return w
+	case *Multi:
+		return &Wire{Errs: toWires(e.Errs)}
+	default:
+		w := &Wire{Message: err.Error()}
+		if errs := unwrapMulti(err); errs != nil {
+			w.Errs = toWires(errs)
+		} else {
+			w.Err = ToWire(errors.Unwrap(err))
+		}
+		return w
+	}
+}
+
+// toWires returns the serializable form of the errors, skipping the nil ones.
+func toWires(errs []error) []Wire {
+	var wires []Wire
+	for _, err := range errs {
+		if err != nil {
+			wires = append(wires, *ToWire(err))
+		}
+	}
+	return wires
+}
+
+// FromWire reconstructs the error chain serialized by ToWire. If w is nil,
+// nil is returned.
+func FromWire(w *Wire) error {
+	if w == nil {
+		return nil
+	}
+
+	if len(w.Errs) > 0 {
+		errs := make([]error, 0, len(w.Errs))
+		for i := range w.Errs {
+			errs = append(errs, FromWire(&w.Errs[i]))
+		}
+		if w.Message != "" {
+			return &wrappedError{msg: w.Message, err: Join(errs...)}
+		}
+		return Join(errs...)
+	}
+
+	if w.Op == "" && w.Code == "" && w.Severity == "" && len(w.KVs) == 0 {
+		if w.Err == nil {
+			return New(w.rootMessage())
+		}
+		if w.Message != "" {
+			return &wrappedError{msg: w.Message, err: FromWire(w.Err)}
+		}
+	}
+
+	e := Error{
+		Op:       Op(w.Op),
+		Code:     Code(w.Code),
+		Severity: Severity(w.Severity),
+		Err:      FromWire(w.Err),
+	}
+	if e.Err == nil {
+		e.Err = New(w.rootMessage())
+	}
+	for _, kv := range w.KVs {
+		e.KVs = append(e.KVs, KV(kv.Key, kv.Value))
+	}
+	return e
+}
+
+// wrappedError is a reconstructed error that wrapped others, like the ones
+// created by fmt.Errorf with %w. It keeps the original message.
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string {
+	return e.msg
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+// rootMessage returns the message of the root error, that is never empty so
+// the reconstructed Error is valid.
+func (w *Wire) rootMessage() string {
+	if w.Message == "" {
+		return "unknown error"
+	}
+	return w.Message
+}
+
+// MarshalJSON marshals the whole error chain as a Wire.
+func (e Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ToWire(e))
+}
+
+// UnmarshalJSON reconstructs the error chain marshaled by MarshalJSON.
+func (e *Error) UnmarshalJSON(b []byte) error {
+	var w Wire
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	err := FromWire(&w)
+	if myErr, ok := err.(Error); ok {
+		*e = myErr
+		return nil
+	}
+	*e = Error{Err: err}
+	return nil
+}
@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWireTestError() error {
+	return E(Op("service.Handle"), Code("INVALID_ITEM"), E(
+		Op("repository.Get"),
+		SeverityInput,
+		KV("item", 42),
+		io.EOF,
+	))
+}
+
+func TestWire_JSON(t *testing.T) {
+	err := newWireTestError()
+
+	b, jsonErr := json.Marshal(err)
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{
+		"op": "service.Handle",
+		"code": "INVALID_ITEM",
+		"err": {
+			"op": "repository.Get",
+			"severity": "input",
+			"kvs": [{"key": "item", "value": "42"}],
+			"err": {"message": "EOF"}
+		}
+	}`, string(b))
+
+	var decoded Error
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.EqualError(t, decoded, err.Error())
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(decoded))
+	assert.Equal(t, SeverityInput, GetSeverity(decoded))
+	assert.Equal(t, Op("service.Handle"), decoded.Op)
+	assert.Equal(t, []KeyValue{KV("item", "42")}, decoded.Err.(Error).KVs)
+}
+
+func TestWire_Multi(t *testing.T) {
+	err := E(Op("batch"), Join(
+		E("invalid item", SeverityInput, Code("INVALID_ITEM")),
+		E("database is down", SeverityFatal),
+	))
+
+	b, jsonErr := json.Marshal(err)
+	require.NoError(t, jsonErr)
+
+	var decoded Error
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.EqualError(t, decoded, "batch: 2 errors: invalid item; database is down")
+	assert.Equal(t, SeverityFatal, GetSeverity(decoded))
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(decoded))
+}
+
+func TestWire_PlainError(t *testing.T) {
+	assert.Nil(t, ToWire(nil))
+	assert.Nil(t, FromWire(nil))
+
+	assert.Equal(t, &Wire{Message: "EOF"}, ToWire(io.EOF))
+	assert.EqualError(t, FromWire(&Wire{Message: "EOF"}), "EOF")
+	assert.EqualError(t, FromWire(&Wire{Code: "MY_CODE"}), "unknown error")
+
+	var decoded Error
+	require.NoError(t, json.Unmarshal([]byte(`{"message":"plain"}`), &decoded))
+	assert.EqualError(t, decoded, "plain")
+}
+
+func TestWire_Avro(t *testing.T) {
+	schema, err := avro.Parse(WireAvroSchema)
+	require.NoError(t, err)
+
+	original := E(Op("batch"), Join(newWireTestError(), New("other")))
+	b, err := avro.Marshal(schema, ToWire(original))
+	require.NoError(t, err)
+
+	var w Wire
+	require.NoError(t, avro.Unmarshal(schema, b, &w))
+	decoded := FromWire(&w)
+	assert.EqualError(t, decoded, original.Error())
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(decoded))
+	assert.Equal(t, SeverityInput, GetSeverity(decoded))
+}
+
+func TestWire_WrappedErrors(t *testing.T) {
+	err := E(Op("service.Handle"), fmt.Errorf("handling: %w", E(Code("INVALID_ITEM"), SeverityInput, "invalid item")))
+
+	decoded := FromWire(ToWire(err))
+
+	assert.EqualError(t, decoded, err.Error())
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(decoded))
+	assert.Equal(t, SeverityInput, GetSeverity(decoded))
+}
+
+func TestWire_JoinedErrors(t *testing.T) {
+	err := errors.Join(E(Code("INVALID_ITEM"), SeverityInput, "invalid item"), E(SeverityFatal, "broken"))
+
+	decoded := FromWire(ToWire(err))
+
+	assert.EqualError(t, decoded, err.Error())
+	assert.Equal(t, SeverityFatal, GetSeverity(decoded))
+	assert.Equal(t, Code("INVALID_ITEM"), GetCode(decoded))
+}
+
+func TestWire_MultiWithNilChild(t *testing.T) {
+	err := &Multi{Errs: []error{nil, New("plain")}}
+
+	var w *Wire
+	assert.NotPanics(t, func() { w = ToWire(err) })
+	assert.EqualError(t, FromWire(w), "plain")
+}
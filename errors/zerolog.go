@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// MarshalZerologObject allows for zerolog to log the error as an object, so
+// log backends can index its parts:
+//
+//   - error: the full message, as returned by Error
+//   - error_message: the message of the root error
+//   - error_ops: the ops of the chain, from the outermost to the innermost
+//   - error_kv: the KVs of the chain with their types, the outermost winning
+//     when a key repeats
+//   - error_code and error_severity: as returned by GetCode and GetSeverity
+//   - error_stack: the stack, if captured
+//   - error_errors: the children, if the root error aggregates many errors,
+//     like Multi
+func (e Error) MarshalZerologObject(ev *zerolog.Event) {
+	if e.Err == nil {
+		return
+	}
+	marshalErrorObject(ev, e)
+}
+
+// errorObject logs an error wrapping an Error, like the ones created by
+// fmt.Errorf with %w, with the fields of Error.MarshalZerologObject.
+type errorObject struct {
+	err error
+}
+
+func (o errorObject) MarshalZerologObject(ev *zerolog.Event) {
+	marshalErrorObject(ev, o.err)
+}
+
+// marshalErrorObject walks the chain of err, including the errors wrapped by
+// other types, and adds its fields to ev.
+func marshalErrorObject(ev *zerolog.Event, err error) {
+	var ops []string
+	kvs := zerolog.Dict()
+	seen := map[string]bool{}
+
+	root := err
+	for {
+		if myErr, ok := root.(Error); ok && myErr.Err != nil {
+			if myErr.Op != "" {
+				ops = append(ops, string(myErr.Op))
+			}
+			for _, kv := range myErr.KVs {
+				key := fmt.Sprint(kv.Key)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				appendKV(kvs, key, kv.Value)
+			}
+			root = myErr.Err
+			continue
+		}
+		if unwrapMulti(root) != nil {
+			break
+		}
+		next := errors.Unwrap(root)
+		if next == nil {
+			break
+		}
+		root = next
+	}
+
+	ev.Str("error", err.Error()).
+		Str("error_message", root.Error()).
+		Strs("error_ops", ops).
+		Dict("error_kv", kvs).
+		EmbedObject(GetCode(err)).
+		EmbedObject(GetSeverity(err))
+	if stack := GetStack(err); stack != nil {
+		ev.Array("error_stack", stack)
+	}
+	if children := unwrapMulti(root); children != nil {
+		ev.Array("error_errors", errorsArray(children))
+	}
+}
+
+// appendKV adds the value to the dict keeping its type when possible.
+func appendKV(d *zerolog.Event, key string, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		d.Interface(key, nil)
+	case error:
+		d.Str(key, v.Error())
+	case time.Duration:
+		d.Dur(key, v)
+	case time.Time:
+		d.Time(key, v)
+	case fmt.Stringer:
+		d.Stringer(key, v)
+	default:
+		d.Interface(key, v)
+	}
+}
+
+// ErrorMarshalFunc is a zerolog.ErrorMarshalFunc that logs errors wrapping an
+// Error, even through fmt.Errorf with %w or Go's errors.Join, as objects with
+// the fields of Error.MarshalZerologObject. Other errors are logged as usual.
+// log.SetupLogger sets it when log.Config.StructuredErrors is enabled. Set it once at the start of the program to apply it to
+// every logged error:
+//
+//	zerolog.ErrorMarshalFunc = errors.ErrorMarshalFunc
+func ErrorMarshalFunc(err error) interface{} {
+	var e Error
+	if errors.As(err, &e) && e.Err != nil {
+		return errorObject{err: err}
+	}
+	return err
+}
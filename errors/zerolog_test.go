@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_MarshalZerologObject(t *testing.T) {
+	err := E(Op("service.Handle"), Code("INVALID_ITEM"), KV("item", 42), E(
+		Op("repository.Get"),
+		SeverityInput,
+		KV("item", 0),
+		KV("found", false),
+		KV("took", time.Second),
+		KV("cause", io.ErrUnexpectedEOF),
+		io.EOF,
+	))
+
+	buf := bytes.Buffer{}
+	logger := zerolog.New(&buf)
+	logger.Log().EmbedObject(err.(Error)).Send()
+
+	assert.JSONEq(t, `{
+		"error": "service.Handle: repository.Get: EOF [item=42,item=0,found=false,took=1s,cause=unexpected EOF]",
+		"error_message": "EOF",
+		"error_ops": ["service.Handle", "repository.Get"],
+		"error_kv": {"item": 42, "found": false, "took": 1000, "cause": "unexpected EOF"},
+		"error_code": "INVALID_ITEM",
+		"error_severity": "input"
+	}`, buf.String())
+}
+
+func TestError_MarshalZerologObject_Multi(t *testing.T) {
+	err := E(Op("batch"), Join(E("invalid item", SeverityInput), New("other")))
+
+	buf := bytes.Buffer{}
+	logger := zerolog.New(&buf)
+	logger.Log().EmbedObject(err.(Error)).Send()
+
+	assert.JSONEq(t, `{
+		"error": "batch: 2 errors: invalid item; other",
+		"error_message": "2 errors: invalid item; other",
+		"error_ops": ["batch"],
+		"error_kv": {},
+		"error_code": "",
+		"error_severity": "input",
+		"error_errors": [
+			{"error": "invalid item", "error_code": "", "error_severity": "input"},
+			{"error": "other", "error_code": "", "error_severity": ""}
+		]
+	}`, buf.String())
+}
+
+func TestErrorMarshalFunc(t *testing.T) {
+	defer func(f func(error) interface{}) { zerolog.ErrorMarshalFunc = f }(zerolog.ErrorMarshalFunc)
+	zerolog.ErrorMarshalFunc = ErrorMarshalFunc
+
+	buf := bytes.Buffer{}
+	logger := zerolog.New(&buf)
+	logger.Log().Err(E(Op("op"), "my error", KV("k", "v"))).Send()
+	assert.JSONEq(t, `{"error": {
+		"error": "op: my error [k=v]",
+		"error_message": "my error",
+		"error_ops": ["op"],
+		"error_kv": {"k": "v"},
+		"error_code": "",
+		"error_severity": ""
+	}}`, buf.String())
+
+	buf.Reset()
+	logger.Log().Err(fmt.Errorf("wrapped: %w", E(Op("op"), "my error", KV("k", "v")))).Send()
+	assert.JSONEq(t, `{"error": {
+		"error": "wrapped: op: my error [k=v]",
+		"error_message": "my error",
+		"error_ops": ["op"],
+		"error_kv": {"k": "v"},
+		"error_code": "",
+		"error_severity": ""
+	}}`, buf.String())
+
+	buf.Reset()
+	logger.Log().Err(io.EOF).Send()
+	assert.JSONEq(t, `{"error": "EOF"}`, buf.String())
+}
@@ -69,11 +69,25 @@ func New(c Config) (endpoint.Middleware, error) {
 
 func doLogging(l *zerolog.Logger, c Config, err error) {
 	if err != nil {
-		l.WithLevel(getErrorLevel(c, err)).
-			Err(err).
+		event := l.WithLevel(getErrorLevel(c, err))
+		if info, ok := errors.LookupCode(errors.GetCode(err)); ok {
+			event = event.Bool("error_retryable", info.Retryable).
+				Str("error_owner", info.Owner)
+		}
+		// Errors wrapping an errors.Error, even through fmt.Errorf or
+		// errors.Join, log their ops, KVs, code, severity and stack as
+		// separate fields
+		if obj, ok := errors.ErrorMarshalFunc(err).(zerolog.LogObjectMarshaler); ok {
+			event.EmbedObject(obj).Msg("Request failed")
+			return
+		}
+		event = event.Err(err).
 			EmbedObject(errors.GetCode(err)).
-			EmbedObject(errors.GetSeverity(err)).
-			Msg("Request failed")
+			EmbedObject(errors.GetSeverity(err))
+		if stack := errors.GetStack(err); stack != nil {
+			event = event.Array("error_stack", stack)
+		}
+		event.Msg("Request failed")
 		return
 	}
 
@@ -27,6 +27,10 @@ import (
 //
 // A value of @maxErrBodySize must be passed to indicate how much of response
 // contents can be added into the error message.
+//
+// If the server responds with an unsuccessful status and an
+// errors.WireErrorResponse body, marked by the errors.WireErrorHeader, the
+// server error is returned with its ops, codes, severities and KVs.
 func CommunicateWithJSON(
 	ctx context.Context,
 	httpClient http.Client,
@@ -191,6 +195,10 @@ func communicateWithJSON(
 		return details, err
 	}
 
+	if err := decodeWireError(details, contents); err != nil {
+		return details, err
+	}
+
 	if err := json.Unmarshal(contents, outResponse); err != nil {
 		contentsStr := string(contents)
 		if len(contentsStr) > maxErrBodySize {
@@ -209,6 +217,27 @@ func communicateWithJSON(
 	return details, nil
 }
 
+// decodeWireError reconstructs the error of an unsuccessful response with an
+// errors.WireErrorResponse body, so the codes and severities of the server
+// errors are kept. Only responses marked by the errors.WireErrorHeader are
+// reconstructed. Other responses are left to be decoded into the output
+// response, returning nil.
+func decodeWireError(details ResponseDetails, contents []byte) error {
+	if details.StatusCode >= 200 && details.StatusCode < 300 {
+		return nil
+	}
+	if details.Header.Get(errors.WireErrorHeader) != errors.WireErrorFormat {
+		return nil
+	}
+
+	var response errors.WireErrorResponse
+	if err := json.Unmarshal(contents, &response); err != nil || response.Error == nil {
+		return nil
+	}
+
+	return errors.E(errors.FromWire(response.Error), errors.KV("HTTP", details.StatusCode))
+}
+
 func makeHTTPRequest(
 	ctx context.Context,
 	fullURL string,
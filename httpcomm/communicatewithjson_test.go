@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/arquivei/foundationkit/apiutil"
 	"github.com/arquivei/foundationkit/errors"
 	"github.com/arquivei/foundationkit/request"
 	"github.com/arquivei/foundationkit/trace"
@@ -258,3 +259,76 @@ func Test_communicateWithJSON_ExpiredContext(t *testing.T) {
 	assert.Equal(t, errors.SeverityRuntime, errors.GetSeverity(err), "Error severity")
 	assert.EqualError(t, errors.GetRootErrorWithKV(err), "refusing request due to expired context [CONTEXT_ERROR=context canceled]", "Error message")
 }
+
+func Test_communicateWithJSON_WireError(t *testing.T) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	encodeError := apiutil.NewHTTPErrorJSONEncoder(apiutil.GetDefaultErrorHTTPStatusCode, apiutil.ParseWireError)
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			encodeError(r.Context(), errors.E(
+				errors.Op("downstream.Handle"),
+				errors.SeverityInput,
+				errors.Code("INVALID_ITEM"),
+				"invalid item",
+				errors.KV("item", 1),
+			), w)
+		},
+	))
+	defer testServer.Close()
+
+	var request struct{}
+	var response struct{}
+
+	details, err := communicateWithJSON(
+		context.Background(),
+		http.Client{},
+		http.MethodPost,
+		testServer.URL,
+		request,
+		nil,
+		2*(1<<10), // 2KB
+		20,
+		/*out*/ &response,
+	)
+
+	assert.Equal(t, http.StatusBadRequest, details.StatusCode, "http status code")
+	assert.Equal(t, errors.Code("INVALID_ITEM"), errors.GetCode(err), "Error code")
+	assert.Equal(t, errors.SeverityInput, errors.GetSeverity(err), "Error severity")
+	assert.EqualError(t, err, "downstream.Handle: invalid item [HTTP=400,item=1]", "Error message")
+}
+
+func Test_communicateWithJSON_ErrorBodyWithoutWireHeader(t *testing.T) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"user not found"}}`))
+		},
+	))
+	defer testServer.Close()
+
+	var request struct{}
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	details, err := communicateWithJSON(
+		context.Background(),
+		http.Client{},
+		http.MethodGet,
+		testServer.URL,
+		request,
+		nil,
+		2*(1<<10), // 2KB
+		20,
+		/*out*/ &response,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, details.StatusCode, "http status code")
+	assert.Equal(t, "user not found", response.Error.Message)
+}
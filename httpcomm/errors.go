@@ -2,6 +2,8 @@ package httpcomm
 
 import "github.com/arquivei/foundationkit/errors"
 
+const owner = "foundationkit/httpcomm"
+
 const (
 	// ErrCodeExpiredContext is returned when an operation won't be
 	// executed due to it's context being expired before the operation
@@ -30,3 +32,38 @@ const (
 	// HTTP Server.
 	ErrCodeMissing errors.Code = "CODE_MISSING"
 )
+
+func init() {
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeExpiredContext,
+		Description: "The request was not sent because its context expired.",
+		Owner:       owner,
+	})
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeRequestError,
+		Retryable:   true,
+		Description: "The request could not be created or sent.",
+		Owner:       owner,
+	})
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeDecodeError,
+		Description: "The response body could not be decoded.",
+		Owner:       owner,
+	})
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeResponseTooLong,
+		Description: "The response body is longer than allowed.",
+		Owner:       owner,
+	})
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeTimeout,
+		Retryable:   true,
+		Description: "The request timed out on the client side.",
+		Owner:       owner,
+	})
+	errors.RegisterCode(errors.CodeInfo{
+		Code:        ErrCodeMissing,
+		Description: "The response has an error without code.",
+		Owner:       owner,
+	})
+}
@@ -29,13 +29,25 @@ func Flatten(value interface{}) string {
 	return strings.Trim(sb.String(), ", ")
 }
 
+// Redacted is the value that replaces secret fields in FlattenMap.
+const Redacted = "[REDACTED]"
+
+// FlattenMap transforms a struct into a map of flattened keys, like a.b.c, to
+// their values. Unlike Flatten, fields tagged with `secret:"true"` are kept,
+// but their values are replaced by Redacted.
+func FlattenMap(value interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	flattenPrefixedToResult(value, "", m, true)
+	return m
+}
+
 func flattenPrefixed(value interface{}, prefix string) map[string]interface{} {
 	m := make(map[string]interface{})
-	flattenPrefixedToResult(value, prefix, m)
+	flattenPrefixedToResult(value, prefix, m, false)
 	return m
 }
 
-func flattenPrefixedToResult(value interface{}, prefix string, m map[string]interface{}) {
+func flattenPrefixedToResult(value interface{}, prefix string, m map[string]interface{}, redact bool) {
 	if value == nil {
 		return
 	}
@@ -51,6 +63,9 @@ func flattenPrefixedToResult(value interface{}, prefix string, m map[string]inte
 		original = reflect.Indirect(original)
 		kind = original.Kind()
 	}
+	if !original.IsValid() {
+		return
+	}
 	t := original.Type()
 
 	switch kind {
@@ -63,21 +78,25 @@ func flattenPrefixedToResult(value interface{}, prefix string, m map[string]inte
 			if !childValue.CanInterface() {
 				continue
 			}
-			flattenPrefixedToResult(childValue.Interface(), base+childKey.String(), m)
+			flattenPrefixedToResult(childValue.Interface(), base+childKey.String(), m, redact)
 		}
 	case reflect.Struct:
 		for i := 0; i < original.NumField(); i++ {
-			isSecretStr, hasTag := t.Field(i).Tag.Lookup("secret")
-			if hasTag && isSecretStr == "true" {
-				continue
-			}
-
 			childValue := original.Field(i)
 			if !childValue.CanInterface() {
 				continue
 			}
 			childKey := t.Field(i).Name
-			flattenPrefixedToResult(childValue.Interface(), base+childKey, m)
+
+			isSecretStr, hasTag := t.Field(i).Tag.Lookup("secret")
+			if hasTag && isSecretStr == "true" {
+				if redact {
+					m[base+childKey] = Redacted
+				}
+				continue
+			}
+
+			flattenPrefixedToResult(childValue.Interface(), base+childKey, m, redact)
 		}
 	default:
 		if prefix != "" {
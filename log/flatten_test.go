@@ -57,3 +57,36 @@ func TestFlatten(t *testing.T) {
 
 	assert.Equal(t, "", Flatten(nil))
 }
+
+func TestFlattenMap(t *testing.T) {
+	var nilPointer *struct{ Foo string }
+	value := struct {
+		Foo    string
+		Nested struct {
+			Bar      int
+			Password string `secret:"true"`
+		}
+		Secret  struct{ Key string } `secret:"true"`
+		Map     map[string]int
+		Pointer *struct{ Foo string }
+		private string
+	}{
+		Foo:     "foo",
+		Map:     map[string]int{"a": 1},
+		Pointer: nilPointer,
+		private: "private",
+	}
+	value.Nested.Bar = 2
+	value.Nested.Password = "q1w2e3"
+	value.Secret.Key = "q1w2e3"
+
+	assert.Equal(t, map[string]interface{}{
+		"Foo":             "foo",
+		"Nested.Bar":      2,
+		"Nested.Password": Redacted,
+		"Secret":          Redacted,
+		"Map.a":           1,
+	}, FlattenMap(value))
+
+	assert.Empty(t, FlattenMap(nil))
+}
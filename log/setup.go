@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/arquivei/foundationkit/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -19,19 +20,28 @@ type Config struct {
 	Hook  struct {
 		Stackdriver bool `default:"false"`
 	}
+	// StructuredErrors logs the errors created by the errors package as
+	// objects, with their ops, KVs, code, severity and stack as separate
+	// fields, instead of a flat message. See errors.ErrorMarshalFunc.
+	StructuredErrors bool `default:"false"`
 }
 
 // SetupLogger sets the global logger by configuring the global zerolog.Log and
-// also the go's log package.
+// also the go's log package. If config.StructuredErrors is set, it also sets
+// zerolog.ErrorMarshalFunc to errors.ErrorMarshalFunc.
 func SetupLogger(config Config, version string, extraLogWriters ...io.Writer) {
+	// Same as the zerolog's global logger
+	var output io.Writer = os.Stderr
 	if config.Human {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
+		output = zerolog.ConsoleWriter{Out: os.Stdout}
 	}
 
+	// Writing to a logger would log the events again as messages, so the
+	// extra writers are combined with the output instead.
 	if len(extraLogWriters) > 0 {
-		extraLogWriters = append(extraLogWriters, log.Logger)
-		log.Logger = log.Output(zerolog.MultiLevelWriter(extraLogWriters...))
+		output = zerolog.MultiLevelWriter(append(extraLogWriters, output)...)
 	}
+	log.Logger = log.Output(output)
 
 	zerolog.SetGlobalLevel(MustParseLevel(config.Level))
 
@@ -46,6 +56,10 @@ func SetupLogger(config Config, version string, extraLogWriters ...io.Writer) {
 		log.Logger = log.Logger.Hook(stackdriverSeverityHook{})
 	}
 
+	if config.StructuredErrors {
+		zerolog.ErrorMarshalFunc = errors.ErrorMarshalFunc
+	}
+
 	// Replace standard go logger with zerolog
 	hooked := log.Hook(noLevelWarnHook{})
 	stdlog.SetFlags(0)
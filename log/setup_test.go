@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/arquivei/foundationkit/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
@@ -62,3 +63,19 @@ func TestSetupLoggerWithContext(t *testing.T) {
 		},
 		"Bla! Bla! Bla!")
 }
+
+func TestSetupLogger_StructuredErrors(t *testing.T) {
+	defer func(f func(error) interface{}) { zerolog.ErrorMarshalFunc = f }(zerolog.ErrorMarshalFunc)
+
+	buffer := bytes.Buffer{}
+	SetupLogger(Config{Level: "info", StructuredErrors: true}, "123", &buffer)
+	log.Logger.Error().Err(errors.E(errors.Op("op"), "my error")).Send()
+
+	var parsedLog struct {
+		Error struct {
+			Ops []string `json:"error_ops"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &parsedLog))
+	assert.Equal(t, []string{"op"}, parsedLog.Error.Ops)
+}
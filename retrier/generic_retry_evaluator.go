@@ -21,12 +21,16 @@ const (
 // happens on blacklists, the error is assumed as non retryable. If a match happens
 // on a whitelist, the errors is assumed as retryable. Both errors codes and errors
 // severity must pass the list tests in order of the error to be retryable.
+// If UseCodeCatalog is set, errors without an explicit severity and with a code
+// registered in the errors catalog are retryable according to the catalog
+// instead of the lists.
 type GenericRetryEvaluator struct {
 	MaxAttempts            int
 	ErrorsCodesPolicy      EvaluationPolicy
 	ErrorsCodes            []errors.Code
 	ErrorsSeveritiesPolicy EvaluationPolicy
 	ErrorsSeverities       []errors.Severity
+	UseCodeCatalog         bool
 }
 
 // GenericRetryEvaluatorSettings is used to construct GenericRetryEvaluator instances.
@@ -44,6 +48,11 @@ type GenericRetryEvaluatorSettings struct {
 	ErrorsSeveritiesPolicy EvaluationPolicy
 	// ErrorsSeveritiesList is the list of errors severity to use as a base. Defaults to empty.
 	ErrorsSeverities []errors.Severity
+	// UseCodeCatalog retries errors with a code registered in the errors
+	// catalog only if the code is retryable, ignoring the lists. Errors with
+	// an explicit severity are still evaluated by the lists, as the severity
+	// wins over the catalog. Defaults to false.
+	UseCodeCatalog bool
 }
 
 // NewGenericRetryEvaluator will return an instance of generic retry evaluator
@@ -58,6 +67,7 @@ func NewGenericRetryEvaluator(settings GenericRetryEvaluatorSettings) *GenericRe
 		ErrorsCodes:            settings.ErrorsCodes,
 		ErrorsSeveritiesPolicy: settings.ErrorsSeveritiesPolicy,
 		ErrorsSeverities:       settings.ErrorsSeverities,
+		UseCodeCatalog:         settings.UseCodeCatalog,
 	}
 }
 
@@ -73,6 +83,12 @@ func (e *GenericRetryEvaluator) IsRetryable(attempt int, attemptError error) boo
 		return false
 	}
 
+	if e.UseCodeCatalog && errors.GetExplicitSeverity(attemptError) == errors.SeverityUnset {
+		if info, ok := errors.LookupCode(errors.GetCode(attemptError)); ok {
+			return info.Retryable
+		}
+	}
+
 	canRetryOnErrorCode, err := isErrorCodeRetryable(errors.GetCode(attemptError), e.ErrorsCodesPolicy, e.ErrorsCodes)
 	if err != nil {
 		panic(errors.E(op, err))
@@ -16,6 +16,18 @@ func TestNewGenericRetryEvaluator_DefaultValues(t *testing.T) {
 	assert.Len(t, evaluator.ErrorsSeverities, 0, "errors severity")
 }
 
+var (
+	catalogRetryableCode = errors.RegisterCode(errors.CodeInfo{
+		Code:      "RETRIER_TEST_RETRYABLE",
+		Severity:  errors.SeverityRuntime,
+		Retryable: true,
+	})
+	catalogNotRetryableCode = errors.RegisterCode(errors.CodeInfo{
+		Code:     "RETRIER_TEST_NOT_RETRYABLE",
+		Severity: errors.SeverityRuntime,
+	})
+)
+
 func TestGenericRetryEvaluator_IsRetryable(t *testing.T) {
 	someErrCode := errors.Code("SOME_CODE")
 	otherErrCode := errors.Code("OTHER_CODE")
@@ -101,6 +113,53 @@ func TestGenericRetryEvaluator_IsRetryable(t *testing.T) {
 			attemptError:        errors.E(someErrCode, errors.SeverityRuntime, "informative error"),
 			expectedIsRetryable: true,
 		},
+		{
+			name: "Error code retryable in the catalog is retryable",
+			settings: GenericRetryEvaluatorSettings{
+				ErrorsSeveritiesPolicy: EvaluationPolicyBlacklist,
+				ErrorsSeverities:       []errors.Severity{errors.SeverityRuntime},
+				UseCodeCatalog:         true,
+			},
+			attemptError:        errors.E(catalogRetryableCode, "informative error"),
+			expectedIsRetryable: true,
+		},
+		{
+			name: "Explicit severity wins over the catalog",
+			settings: GenericRetryEvaluatorSettings{
+				ErrorsSeveritiesPolicy: EvaluationPolicyBlacklist,
+				ErrorsSeverities:       []errors.Severity{errors.SeverityFatal},
+				UseCodeCatalog:         true,
+			},
+			attemptError:        errors.E(catalogRetryableCode, errors.SeverityFatal, "informative error"),
+			expectedIsRetryable: false,
+		},
+		{
+			name: "Error code not retryable in the catalog is not retryable",
+			settings: GenericRetryEvaluatorSettings{
+				UseCodeCatalog: true,
+			},
+			attemptError:        errors.E(catalogNotRetryableCode, "informative error"),
+			expectedIsRetryable: false,
+		},
+		{
+			name: "Error code not in the catalog uses the lists",
+			settings: GenericRetryEvaluatorSettings{
+				ErrorsCodesPolicy: EvaluationPolicyBlacklist,
+				ErrorsCodes:       []errors.Code{someErrCode},
+				UseCodeCatalog:    true,
+			},
+			attemptError:        errors.E(someErrCode, "informative error"),
+			expectedIsRetryable: false,
+		},
+		{
+			name: "Catalog is ignored if not used",
+			settings: GenericRetryEvaluatorSettings{
+				ErrorsSeveritiesPolicy: EvaluationPolicyBlacklist,
+				ErrorsSeverities:       []errors.Severity{errors.SeverityRuntime},
+			},
+			attemptError:        errors.E(catalogRetryableCode, "informative error"),
+			expectedIsRetryable: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -1,35 +1,83 @@
 package accesskey
 
-import (
-	"github.com/arquivei/foundationkit/errors"
-)
+import "github.com/arquivei/foundationkit/errors"
+
+const owner = "foundationkit/sefaz/accesskey"
 
 var (
 	// ErrCodeEmptyAccessKey is an error code used to imply that an access key provided was empty
-	ErrCodeEmptyAccessKey = errors.Code("EMPTY_ACCESS_KEY")
+	ErrCodeEmptyAccessKey = errors.RegisterCode(errors.CodeInfo{
+		Code:        "EMPTY_ACCESS_KEY",
+		Description: "The access key is empty.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidLength is an error code used to imply that an access key does not contains 44 digits
-	ErrCodeInvalidLength = errors.Code("INVALID_LENGTH")
+	ErrCodeInvalidLength = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_LENGTH",
+		Description: "The access key does not have 44 digits.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidCharacter is an error code used to imply that an access key has non-numeric character(s)
-	ErrCodeInvalidCharacter = errors.Code("INVALID_CHARACTER")
+	ErrCodeInvalidCharacter = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_CHARACTER",
+		Description: "The access key has non-numeric characters.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidUF is an error code used to imply that an access key does not contain a valid IBGE UF code
-	ErrCodeInvalidUF = errors.Code("INVALID_UF")
+	ErrCodeInvalidUF = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_UF",
+		Description: "The access key does not have a valid IBGE UF code.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidMonth is an error code used to imply that an access key does not contain a month value between 01-12
-	ErrCodeInvalidMonth = errors.Code("INVALID_MONTH")
+	ErrCodeInvalidMonth = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_MONTH",
+		Description: "The access key does not have a month between 01 and 12.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidCPFCNPJ is an error code used to imply that an access key does not contain a valid CNPJ
-	ErrCodeInvalidCPFCNPJ = errors.Code("INVALID_CPF_CNPJ")
+	ErrCodeInvalidCPFCNPJ = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_CPF_CNPJ",
+		Description: "The access key does not have a valid CPF or CNPJ.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidModel is an error code used to imply that an access key does not contain a valid SEFAZ model
-	ErrCodeInvalidModel = errors.Code("INVALID_MODEL")
+	ErrCodeInvalidModel = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_MODEL",
+		Description: "The access key does not have a valid SEFAZ model.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidDigit is an error code used to imply that an access has a verification digit mismatch
-	ErrCodeInvalidDigit = errors.Code("INVALID_DIGIT")
+	ErrCodeInvalidDigit = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_DIGIT",
+		Description: "The access key verification digit does not match.",
+		Owner:       owner,
+	})
 
 	// ErrCodeInvalidSerieForNFF
-	ErrCodeInvalidSerieForNFF = errors.Code("INVALID_SERIE_FOR_NFF")
+	ErrCodeInvalidSerieForNFF = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_SERIE_FOR_NFF",
+		Description: "The access key has an invalid serie for NFF.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidCodigoForNFF
-	ErrCodeInvalidNumeroForNFF = errors.Code("INVALID_NUMERO_FOR_NFF")
+	ErrCodeInvalidNumeroForNFF = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_NUMERO_FOR_NFF",
+		Description: "The access key has an invalid numero for NFF.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidCNPJForNFF
-	ErrCodeInvalidCNPJForNFF = errors.Code("INVALID_CNPJ_FOR_NFF")
+	ErrCodeInvalidCNPJForNFF = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_CNPJ_FOR_NFF",
+		Description: "The access key has an invalid CNPJ for NFF.",
+		Owner:       owner,
+	})
 	// ErrCodeInvalidCPFForNFF
-	ErrCodeInvalidCPFForNFF = errors.Code("INVALID_CPF_FOR_NFF")
+	ErrCodeInvalidCPFForNFF = errors.RegisterCode(errors.CodeInfo{
+		Code:        "INVALID_CPF_FOR_NFF",
+		Description: "The access key has an invalid CPF for NFF.",
+		Owner:       owner,
+	})
 
 	// ErrEmptyAccessKey is returned when the provided access key is an empty string
 	ErrEmptyAccessKey = errors.New("access key is empty")
@@ -43,35 +43,35 @@ func validate(accessKey AccessKey) error {
 	const op errors.Op = "validate"
 
 	if accessKey == "" {
-		return errors.E(op, ErrEmptyAccessKey, ErrCodeEmptyAccessKey)
+		return errors.E(op, ErrEmptyAccessKey, ErrCodeEmptyAccessKey, errors.SeverityInput)
 	}
 
 	if len(accessKey) != 44 {
-		return errors.E(op, ErrInvalidLength, ErrCodeInvalidLength)
+		return errors.E(op, ErrInvalidLength, ErrCodeInvalidLength, errors.SeverityInput)
 	}
 
 	if !isDigitOnly(accessKey) {
-		return errors.E(op, ErrInvalidCharacter, ErrCodeInvalidCharacter)
+		return errors.E(op, ErrInvalidCharacter, ErrCodeInvalidCharacter, errors.SeverityInput)
 	}
 
 	if !isValidUF(accessKey[0:2].String()) {
-		return errors.E(op, ErrInvalidUF, ErrCodeInvalidUF)
+		return errors.E(op, ErrInvalidUF, ErrCodeInvalidUF, errors.SeverityInput)
 	}
 
 	if !isValidMonth(accessKey[4:6].String()) {
-		return errors.E(op, ErrInvalidMonth, ErrCodeInvalidMonth)
+		return errors.E(op, ErrInvalidMonth, ErrCodeInvalidMonth, errors.SeverityInput)
 	}
 
 	if !isValidCPFCNPJ(accessKey[6:20].String()) {
-		return errors.E(op, ErrInvalidCPFCNPJ, ErrCodeInvalidCPFCNPJ)
+		return errors.E(op, ErrInvalidCPFCNPJ, ErrCodeInvalidCPFCNPJ, errors.SeverityInput)
 	}
 
 	if !isValidModel(accessKey[20:22].String()) {
-		return errors.E(op, ErrInvalidModel, ErrCodeInvalidModel)
+		return errors.E(op, ErrInvalidModel, ErrCodeInvalidModel, errors.SeverityInput)
 	}
 
 	if !isValidationDigitCorrect(accessKey.String()) {
-		return errors.E(op, ErrInvalidDigit, ErrCodeInvalidDigit)
+		return errors.E(op, ErrInvalidDigit, ErrCodeInvalidDigit, errors.SeverityInput)
 	}
 
 	return nil
@@ -90,26 +90,26 @@ func validateNFF(accessKey AccessKey) error {
 	const op errors.Op = "validateNFF"
 
 	if !isValidSerieForNFF(accessKey[22:25].String()) {
-		return errors.E(op, ErrInvalidSerieForNFF, ErrCodeInvalidSerieForNFF)
+		return errors.E(op, ErrInvalidSerieForNFF, ErrCodeInvalidSerieForNFF, errors.SeverityInput)
 	}
 
 	if !isValidNumeroForNFF(accessKey[25:34].String()) {
-		return errors.E(op, ErrInvalidNumeroForNFF, ErrCodeInvalidNumeroForNFF)
+		return errors.E(op, ErrInvalidNumeroForNFF, ErrCodeInvalidNumeroForNFF, errors.SeverityInput)
 	}
 
 	switch accessKey[29] {
 	case '1':
 		err := stakeholder.CheckCNPJ(accessKey[6:20].String())
 		if err != nil {
-			return errors.E(op, err, ErrCodeInvalidCNPJForNFF)
+			return errors.E(op, err, ErrCodeInvalidCNPJForNFF, errors.SeverityInput)
 		}
 	case '2':
 		if accessKey[6:9] != "000" {
-			return errors.E(op, "cpf is not padded with 0", ErrCodeInvalidCPFForNFF)
+			return errors.E(op, "cpf is not padded with 0", ErrCodeInvalidCPFForNFF, errors.SeverityInput)
 		}
 		err := stakeholder.CheckCPF(accessKey[9:20].String())
 		if err != nil {
-			return errors.E(op, err, ErrCodeInvalidCPFForNFF)
+			return errors.E(op, err, ErrCodeInvalidCPFForNFF, errors.SeverityInput)
 		}
 	}
 
@@ -153,3 +153,13 @@ func TestCheck_ShouldReturnValidationCodesForNFF(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorCodes_DoNotChangeServiceErrors(t *testing.T) {
+	assert.NotPanics(t, func() {
+		errors.RegisterCode(errors.CodeInfo{Code: "INVALID_LENGTH", Severity: errors.SeverityRuntime, Owner: "service"})
+	})
+
+	assert.Equal(t, ErrCodeInvalidLength, errors.GetCode(Check(AccessKey("123"))))
+	assert.Equal(t, errors.SeverityInput, errors.GetSeverity(Check(AccessKey("123"))))
+	assert.Equal(t, errors.SeverityUnset, errors.GetSeverity(errors.E(errors.Code("INVALID_MONTH"), "my error")))
+}